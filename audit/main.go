@@ -0,0 +1,89 @@
+package audit
+
+import (
+	"net/url"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/sirupsen/logrus"
+)
+
+// Actions recorded for changes made on Discord.
+const (
+	ActionNicknameUpdate = "nickname.update"
+	ActionRoleAdd        = "role.add"
+	ActionRoleRemove     = "role.remove"
+	ActionMessageEdit    = "message.edit"
+)
+
+// Outcomes of an audited change.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+	OutcomeDryRun  = "dry_run"
+)
+
+// MaxReasonLength is the longest X-Audit-Log-Reason Discord accepts.
+const MaxReasonLength = 512
+
+// Entry describes a change made on Discord: who asked for it, who or what it
+// changed and why. Details holds extra context such as the role or nickname.
+type Entry struct {
+	Actor   string
+	Target  string
+	Action  string
+	Reason  string
+	Outcome string
+	Error   string
+	Details map[string]string
+}
+
+// Write stores the audit record of entry. It is a variable so tests can
+// capture records.
+var Write = func(entry Entry) {
+	fields := logrus.Fields{
+		"audit":   true,
+		"actor":   entry.Actor,
+		"target":  entry.Target,
+		"action":  entry.Action,
+		"reason":  entry.Reason,
+		"outcome": entry.Outcome,
+	}
+	for key, value := range entry.Details {
+		fields[key] = value
+	}
+	if entry.Outcome == OutcomeFailure {
+		logrus.WithFields(fields).Errorf("Audited change failed: %s", entry.Error)
+		return
+	}
+	logrus.WithFields(fields).Info("Audited change")
+}
+
+// Reason returns the X-Audit-Log-Reason request option for entry, cut to the
+// length Discord accepts. Discord expects the header to be URL encoded, which
+// discordgo does not do.
+func Reason(entry Entry) discordgo.RequestOption {
+	reason := []rune(entry.Reason)
+	if len(reason) > MaxReasonLength {
+		reason = reason[:MaxReasonLength]
+	}
+	return discordgo.WithAuditLogReason(url.PathEscape(string(reason)))
+}
+
+// Apply makes a change on Discord with entry's reason attached and writes the
+// audit record of its outcome.
+func Apply(entry Entry, change func(reason discordgo.RequestOption) error) error {
+	err := change(Reason(entry))
+	entry.Outcome = OutcomeSuccess
+	if err != nil {
+		entry.Outcome = OutcomeFailure
+		entry.Error = err.Error()
+	}
+	Write(entry)
+	return err
+}
+
+// DryRun writes the audit record of a change that was only planned.
+func DryRun(entry Entry) {
+	entry.Outcome = OutcomeDryRun
+	Write(entry)
+}
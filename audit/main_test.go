@@ -0,0 +1,69 @@
+package audit
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+)
+
+func captureEntries(t *testing.T) *[]Entry {
+	originalWrite := Write
+	t.Cleanup(func() { Write = originalWrite })
+	entries := &[]Entry{}
+	Write = func(entry Entry) {
+		*entries = append(*entries, entry)
+	}
+	return entries
+}
+
+func reasonHeader(option discordgo.RequestOption) string {
+	config := &discordgo.RequestConfig{Request: &http.Request{Header: http.Header{}}}
+	option(config)
+	return config.Request.Header.Get("X-Audit-Log-Reason")
+}
+
+func TestApply(t *testing.T) {
+	entry := Entry{Actor: "user:1", Target: "2", Action: ActionNicknameUpdate, Reason: "listening mode enabled via /listening by joy"}
+
+	t.Run("should attach the reason and record a successful change", func(t *testing.T) {
+		entries := captureEntries(t)
+		var header string
+		err := Apply(entry, func(reason discordgo.RequestOption) error {
+			header = reasonHeader(reason)
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, "listening%20mode%20enabled%20via%20%2Flistening%20by%20joy", header)
+		assert.Len(t, *entries, 1)
+		assert.Equal(t, OutcomeSuccess, (*entries)[0].Outcome)
+		assert.Equal(t, "user:1", (*entries)[0].Actor)
+	})
+
+	t.Run("should record a failed change and return its error", func(t *testing.T) {
+		entries := captureEntries(t)
+		err := Apply(entry, func(reason discordgo.RequestOption) error {
+			return errors.New("missing permissions")
+		})
+		assert.Error(t, err)
+		assert.Equal(t, OutcomeFailure, (*entries)[0].Outcome)
+		assert.Equal(t, "missing permissions", (*entries)[0].Error)
+	})
+
+	t.Run("should cut reasons to the length Discord accepts", func(t *testing.T) {
+		long := Entry{Reason: strings.Repeat("é", MaxReasonLength+10)}
+		header := reasonHeader(Reason(long))
+		assert.Equal(t, strings.Repeat("%C3%A9", MaxReasonLength), header)
+	})
+}
+
+func TestDryRun(t *testing.T) {
+	t.Run("should record the planned change", func(t *testing.T) {
+		entries := captureEntries(t)
+		DryRun(Entry{Action: ActionRoleAdd})
+		assert.Equal(t, OutcomeDryRun, (*entries)[0].Outcome)
+	})
+}
@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/Real-Dev-Squad/discord-service/commands/register"
+	"github.com/Real-Dev-Squad/discord-service/models"
+	"github.com/bwmarrin/discordgo"
+)
+
+func runCommands(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(out, usage)
+		return fmt.Errorf("%w: missing commands subcommand", ErrUsage)
+	}
+
+	flags := flag.NewFlagSet("commands "+args[0], flag.ContinueOnError)
+	flags.SetOutput(out)
+	dryRun := flags.Bool("dry-run", false, "only show the changes")
+	if err := flags.Parse(args[1:]); err != nil {
+		return fmt.Errorf("%w: %v", ErrUsage, err)
+	}
+	if *dryRun && args[0] != "register" {
+		return fmt.Errorf("%w: --dry-run is only supported by commands register, use commands diff to preview changes", ErrUsage)
+	}
+
+	var run func(session models.SessionInterface) error
+	switch args[0] {
+	case "register":
+		run = func(session models.SessionInterface) error {
+			diff, err := register.RegisterCommands(session, *dryRun)
+			printDiff(out, diff)
+			return err
+		}
+	case "diff":
+		run = func(session models.SessionInterface) error {
+			diff, err := register.PlanCommands(session)
+			printDiff(out, diff)
+			return err
+		}
+	case "list":
+		run = func(session models.SessionInterface) error {
+			registered, err := register.ListCommands(session)
+			for _, scope := range registered {
+				for _, command := range scope.Commands {
+					fmt.Fprintf(out, "%s\t%s\t%s\t%s\n", register.ScopeName(scope.GuildID), command.Name, command.ID, command.Description)
+				}
+			}
+			return err
+		}
+	case "delete":
+		if flags.NArg() == 0 {
+			return fmt.Errorf("%w: commands delete needs at least one command name", ErrUsage)
+		}
+		run = func(session models.SessionInterface) error {
+			deleted, err := register.DeleteCommands(session, flags.Args())
+			for _, name := range deleted {
+				fmt.Fprintf(out, "deleted %s\n", name)
+			}
+			return err
+		}
+	default:
+		fmt.Fprint(out, usage)
+		return fmt.Errorf("%w: unknown commands subcommand %q", ErrUsage, args[0])
+	}
+
+	if err := Setup(); err != nil {
+		return err
+	}
+	session, err := register.Connect()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	return run(session)
+}
+
+func printDiff(out io.Writer, diffs []register.ScopedDiff) {
+	for _, diff := range diffs {
+		fmt.Fprintf(out, "%s:\n", register.ScopeName(diff.GuildID))
+		print := func(mark string, commands []*discordgo.ApplicationCommand) {
+			for _, name := range register.CommandNames(commands) {
+				fmt.Fprintf(out, "  %s %s\n", mark, name)
+			}
+		}
+		print("+", diff.Create)
+		print("~", diff.Update)
+		print("-", diff.Delete)
+		print("=", diff.Unchanged)
+	}
+}
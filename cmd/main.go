@@ -0,0 +1,78 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/Real-Dev-Squad/discord-service/commands/constants"
+	"github.com/Real-Dev-Squad/discord-service/config"
+	"github.com/sirupsen/logrus"
+)
+
+const usage = `Usage: discord-service <command> [arguments]
+
+Commands:
+  serve                          start the HTTP server (default)
+  commands register [--dry-run]  register the slash commands defined in code
+  commands list                  list the registered slash commands
+  commands diff                  show what commands register would change
+  commands delete <name>...      delete registered slash commands
+`
+
+var ErrUsage = errors.New("invalid usage")
+
+// Run runs the command named by args, writing its output to out. Without
+// arguments it starts the server.
+func Run(args []string, out io.Writer) error {
+	if len(args) == 0 || args[0] == "serve" {
+		if err := Setup(); err != nil {
+			return err
+		}
+		return Serve()
+	}
+	switch args[0] {
+	case "commands":
+		return runCommands(args[1:], out)
+	case "help", "-h", "--help":
+		fmt.Fprint(out, usage)
+		return nil
+	default:
+		fmt.Fprint(out, usage)
+		return fmt.Errorf("%w: unknown command %q", ErrUsage, args[0])
+	}
+}
+
+// Setup loads the configuration, and the commands file when COMMANDS_FILE is
+// set, before a command that needs them runs.
+var Setup = func() error {
+	appConfig, err := config.Load()
+	if err != nil {
+		return err
+	}
+	config.AppConfig = appConfig
+	logrus.SetLevel(appConfig.LOG_LEVEL)
+	if appConfig.COMMANDS_FILE != "" {
+		commands, err := constants.LoadCommands(appConfig.COMMANDS_FILE)
+		if err != nil {
+			return err
+		}
+		constants.Commands = commands
+	}
+	logrus.Infof("Running in %s, registering commands at the %s scope", appConfig.ENV, appConfig.REGISTRATION_SCOPE)
+	warnUnknownFlags()
+	return nil
+}
+
+// warnUnknownFlags warns about DISABLED_COMMANDS that name no declared command,
+// which usually is a typo that leaves the command on.
+func warnUnknownFlags() {
+	for _, name := range config.AppConfig.DISABLED_COMMANDS.List() {
+		if constants.FindCommand(name) == nil {
+			logrus.Warnf("DISABLED_COMMANDS names %q, which is not a declared command", name)
+		}
+	}
+	if disabled := config.AppConfig.DISABLED_COMMANDS.List(); len(disabled) > 0 {
+		logrus.Infof("Disabled commands: %v", disabled)
+	}
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/Real-Dev-Squad/discord-service/commands/register"
+	"github.com/Real-Dev-Squad/discord-service/config"
+	"github.com/Real-Dev-Squad/discord-service/models"
+	_ "github.com/Real-Dev-Squad/discord-service/tests/helpers"
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockSession only has commands registered globally.
+type mockSession struct {
+	registered []*discordgo.ApplicationCommand
+	created    []string
+	deleted    []string
+	closed     bool
+}
+
+func (m *mockSession) Close() error { m.closed = true; return nil }
+func (m *mockSession) CurrentApplication() (*discordgo.Application, error) {
+	return &discordgo.Application{ID: "application"}, nil
+}
+
+func (m *mockSession) ApplicationCommandCreate(applicationID, guildID string, command *discordgo.ApplicationCommand) (*discordgo.ApplicationCommand, error) {
+	m.created = append(m.created, command.Name)
+	return command, nil
+}
+
+func (m *mockSession) ApplicationCommands(applicationID, guildID string) ([]*discordgo.ApplicationCommand, error) {
+	if guildID != "" {
+		return nil, nil
+	}
+	return m.registered, nil
+}
+
+func (m *mockSession) ApplicationCommandEdit(applicationID, guildID, commandID string, command *discordgo.ApplicationCommand) (*discordgo.ApplicationCommand, error) {
+	return command, nil
+}
+
+func (m *mockSession) ApplicationCommandDelete(applicationID, guildID, commandID string) error {
+	m.deleted = append(m.deleted, commandID)
+	return nil
+}
+
+func mockSetup(t *testing.T, err error) *bool {
+	originalSetup := Setup
+	t.Cleanup(func() { Setup = originalSetup })
+	called := false
+	Setup = func() error {
+		called = true
+		return err
+	}
+	return &called
+}
+
+func mockConnect(t *testing.T, session *mockSession) {
+	mockSetup(t, nil)
+	originalConnect := register.Connect
+	t.Cleanup(func() { register.Connect = originalConnect })
+	register.Connect = func() (models.SessionInterface, error) {
+		return session, nil
+	}
+}
+
+func TestRun(t *testing.T) {
+	t.Run("should serve without arguments", func(t *testing.T) {
+		originalServe := Serve
+		defer func() { Serve = originalServe }()
+		served := false
+		Serve = func() error {
+			served = true
+			return nil
+		}
+		setup := mockSetup(t, nil)
+		assert.NoError(t, Run([]string{}, &bytes.Buffer{}))
+		assert.True(t, served)
+		assert.True(t, *setup)
+	})
+
+	t.Run("should not serve with an invalid configuration", func(t *testing.T) {
+		originalServe := Serve
+		defer func() { Serve = originalServe }()
+		served := false
+		Serve = func() error {
+			served = true
+			return nil
+		}
+		mockSetup(t, assert.AnError)
+		assert.ErrorIs(t, Run([]string{"serve"}, &bytes.Buffer{}), assert.AnError)
+		assert.False(t, served)
+	})
+
+	t.Run("should print usage without loading the configuration", func(t *testing.T) {
+		setup := mockSetup(t, assert.AnError)
+		assert.NoError(t, Run([]string{"help"}, &bytes.Buffer{}))
+		assert.False(t, *setup)
+	})
+
+	t.Run("should print usage for help", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		assert.NoError(t, Run([]string{"help"}, out))
+		assert.Contains(t, out.String(), "commands register")
+	})
+
+	t.Run("should return a usage error for unknown commands", func(t *testing.T) {
+		assert.ErrorIs(t, Run([]string{"unknown"}, &bytes.Buffer{}), ErrUsage)
+		assert.ErrorIs(t, Run([]string{"commands"}, &bytes.Buffer{}), ErrUsage)
+		assert.ErrorIs(t, Run([]string{"commands", "unknown"}, &bytes.Buffer{}), ErrUsage)
+		assert.ErrorIs(t, Run([]string{"commands", "delete"}, &bytes.Buffer{}), ErrUsage)
+	})
+}
+
+func TestRunCommands(t *testing.T) {
+	stale := &discordgo.ApplicationCommand{ID: "stale-id", Name: "stale", Description: "old"}
+
+	t.Run("should not connect with an invalid configuration", func(t *testing.T) {
+		mockSetup(t, assert.AnError)
+		assert.ErrorIs(t, Run([]string{"commands", "list"}, &bytes.Buffer{}), assert.AnError)
+	})
+
+	t.Run("should show the changes without making them in a dry run", func(t *testing.T) {
+		session := &mockSession{registered: []*discordgo.ApplicationCommand{stale}}
+		mockConnect(t, session)
+		out := &bytes.Buffer{}
+		assert.NoError(t, Run([]string{"commands", "register", "--dry-run"}, out))
+		assert.Contains(t, out.String(), "global:\n  + hello\n  - stale\n")
+		assert.Contains(t, out.String(), "guild "+config.AppConfig.GUILD_ID+":\n  + listening\n")
+		assert.Empty(t, session.created)
+		assert.Empty(t, session.deleted)
+		assert.True(t, session.closed)
+	})
+
+	t.Run("should register the commands", func(t *testing.T) {
+		session := &mockSession{registered: []*discordgo.ApplicationCommand{stale}}
+		mockConnect(t, session)
+		assert.NoError(t, Run([]string{"commands", "register"}, &bytes.Buffer{}))
+		assert.Contains(t, session.created, "hello")
+		assert.Equal(t, []string{"stale-id"}, session.deleted)
+	})
+
+	t.Run("should show the diff", func(t *testing.T) {
+		session := &mockSession{registered: []*discordgo.ApplicationCommand{stale}}
+		mockConnect(t, session)
+		out := &bytes.Buffer{}
+		assert.NoError(t, Run([]string{"commands", "diff"}, out))
+		assert.Contains(t, out.String(), "  - stale\n")
+		assert.Empty(t, session.deleted)
+	})
+
+	t.Run("should list the registered commands", func(t *testing.T) {
+		mockConnect(t, &mockSession{registered: []*discordgo.ApplicationCommand{stale}})
+		out := &bytes.Buffer{}
+		assert.NoError(t, Run([]string{"commands", "list"}, out))
+		assert.Equal(t, "global\tstale\tstale-id\told\n", out.String())
+	})
+
+	t.Run("should delete the named commands", func(t *testing.T) {
+		session := &mockSession{registered: []*discordgo.ApplicationCommand{stale}}
+		mockConnect(t, session)
+		out := &bytes.Buffer{}
+		assert.NoError(t, Run([]string{"commands", "delete", "stale"}, out))
+		assert.Equal(t, "deleted stale\n", out.String())
+		assert.Equal(t, []string{"stale-id"}, session.deleted)
+	})
+
+	t.Run("should refuse a dry run outside register instead of deleting", func(t *testing.T) {
+		session := &mockSession{registered: []*discordgo.ApplicationCommand{stale}}
+		mockConnect(t, session)
+		assert.ErrorIs(t, Run([]string{"commands", "delete", "--dry-run", "stale"}, &bytes.Buffer{}), ErrUsage)
+		assert.Empty(t, session.deleted)
+	})
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+
+	"github.com/Real-Dev-Squad/discord-service/commands/handlers"
+	"github.com/Real-Dev-Squad/discord-service/config"
+	"github.com/sirupsen/logrus"
+)
+
+var reloadConfig = config.Reload
+
+// reloadOnHangup reloads the secrets and feature flags every time the process receives SIGHUP,
+// until the returned function is called.
+func reloadOnHangup() func() {
+	hangup := make(chan os.Signal, 1)
+	signal.Notify(hangup, syscall.SIGHUP)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-hangup:
+				reload()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		signal.Stop(hangup)
+		close(done)
+	}
+}
+
+// reload reloads the configuration and recreates the Discord client when the
+// bot token changed. A failed reload keeps the current secrets and flags.
+func reload() {
+	changed, err := reloadConfig()
+	if err != nil {
+		logrus.Errorf("Keeping the current secrets and flags, reloading failed: %v", err)
+		return
+	}
+	if len(changed) == 0 {
+		logrus.Info("Reloaded the configuration, nothing changed")
+		return
+	}
+	logrus.Infof("Reloaded %v", changed)
+	if slices.Contains(changed, "DISABLED_COMMANDS") {
+		warnUnknownFlags()
+	}
+	if !slices.Contains(changed, "BOT_TOKEN") {
+		return
+	}
+	discord, err := handlers.NewDiscordClient()
+	if err != nil {
+		logrus.Errorf("Cannot recreate the Discord client with the new bot token: %v", err)
+		return
+	}
+	if previous := handlers.SetDiscordClient(discord); previous != nil {
+		if err := previous.Close(); err != nil {
+			logrus.Errorf("Cannot close the Discord client of the previous bot token: %v", err)
+		}
+	}
+	if config.AppConfig.GATEWAY_ENABLED {
+		logrus.Warn("The gateway connection keeps using the previous bot token until the service restarts")
+	}
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/Real-Dev-Squad/discord-service/commands/handlers"
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockReload makes the next reload report changed, and counts the Discord
+// clients created afterwards.
+func mockReload(t *testing.T, changed []string, err error) *int {
+	originalReload := reloadConfig
+	originalNewDiscord := handlers.NewDiscord
+	t.Cleanup(func() {
+		reloadConfig = originalReload
+		handlers.NewDiscord = originalNewDiscord
+	})
+	reloads := 0
+	reloadConfig = func() ([]string, error) {
+		reloads++
+		return changed, err
+	}
+	created := 0
+	handlers.NewDiscord = func(token string) (*discordgo.Session, error) {
+		created++
+		return originalNewDiscord(token)
+	}
+	t.Cleanup(func() { assert.Positive(t, reloads) })
+	return &created
+}
+
+type closingSession struct {
+	handlers.DiscordSessionWrapper
+	closed bool
+}
+
+func (s *closingSession) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestReload(t *testing.T) {
+	t.Run("should recreate the Discord client when the bot token changed", func(t *testing.T) {
+		created := mockReload(t, []string{"BOT_TOKEN"}, nil)
+		reload()
+		assert.Equal(t, 1, *created)
+	})
+
+	t.Run("should close the Discord client it replaced", func(t *testing.T) {
+		mockReload(t, []string{"BOT_TOKEN"}, nil)
+		previous := &closingSession{}
+		original := handlers.SetDiscordClient(previous)
+		defer handlers.SetDiscordClient(original)
+		reload()
+		assert.True(t, previous.closed)
+	})
+
+	t.Run("should keep the Discord client when other secrets changed", func(t *testing.T) {
+		created := mockReload(t, []string{"BOT_PRIVATE_KEY"}, nil)
+		reload()
+		assert.Zero(t, *created)
+	})
+
+	t.Run("should keep the Discord client when reloading failed", func(t *testing.T) {
+		created := mockReload(t, nil, errors.New("invalid configuration"))
+		reload()
+		assert.Zero(t, *created)
+	})
+}
+
+func TestReloadOnHangup(t *testing.T) {
+	t.Run("should reload on SIGHUP", func(t *testing.T) {
+		reloaded := make(chan struct{}, 1)
+		originalReload := reloadConfig
+		defer func() { reloadConfig = originalReload }()
+		reloadConfig = func() ([]string, error) {
+			reloaded <- struct{}{}
+			return nil, nil
+		}
+
+		stop := reloadOnHangup()
+		defer stop()
+		assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGHUP))
+		select {
+		case <-reloaded:
+		case <-time.After(time.Second):
+			t.Fatal("configuration was not reloaded")
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"syscall"
+
+	"github.com/Real-Dev-Squad/discord-service/commands/handlers"
+	"github.com/Real-Dev-Squad/discord-service/config"
+	"github.com/Real-Dev-Squad/discord-service/gateway"
+	"github.com/Real-Dev-Squad/discord-service/lifecycle"
+	"github.com/Real-Dev-Squad/discord-service/queue"
+	"github.com/Real-Dev-Squad/discord-service/routes"
+	"github.com/Real-Dev-Squad/discord-service/scheduler"
+	"github.com/sirupsen/logrus"
+)
+
+// Serve starts the server and runs it until SIGINT or SIGTERM. Commands are
+// registered separately with "commands register", so a Discord outage cannot
+// keep the server from starting.
+var Serve = func() error {
+	manager := lifecycle.New()
+	failed, err := start(manager)
+	if err != nil {
+		return errors.Join(err, manager.Shutdown(config.AppConfig.SHUTDOWN_TIMEOUT))
+	}
+	return manager.Run(failed, config.AppConfig.SHUTDOWN_TIMEOUT, syscall.SIGINT, syscall.SIGTERM)
+}
+
+// start starts every part of the service, registering how to stop each one.
+// Parts are stopped in reverse: the HTTP server stops accepting interactions
+// and drains its handlers first, then the producers of queued jobs stop, then
+// the AMQP connection and last the Discord client the jobs used are closed.
+func start(manager *lifecycle.Manager) (<-chan error, error) {
+	discord, err := handlers.NewDiscordClient()
+	if err != nil {
+		return nil, err
+	}
+	handlers.SetDiscordClient(discord)
+	manager.OnStop("Discord client", func(context.Context) error { return handlers.CloseDiscordClient() })
+
+	queue.GetQueueInstance()
+	manager.OnStop("AMQP connection", func(context.Context) error { return queue.Close() })
+
+	if config.AppConfig.GATEWAY_ENABLED {
+		session, err := gateway.NewSession()
+		if err != nil {
+			return nil, err
+		}
+		worker := gateway.NewWorker(session)
+		gateway.RegisterMemberSync(worker)
+		if err := worker.Start(); err != nil {
+			return nil, err
+		}
+		manager.OnStop("gateway", func(context.Context) error { return worker.Stop() })
+	}
+
+	if interval := config.AppConfig.NICKNAME_RECONCILE_INTERVAL; interval > 0 {
+		stop := scheduler.Every("nickname reconciliation", interval, handlers.EnqueueNicknameReconcile)
+		manager.OnStop("nickname reconciliation", func(context.Context) error { stop(); return nil })
+	}
+
+	stopReload := reloadOnHangup()
+	manager.OnStop("reload on SIGHUP", func(context.Context) error { stopReload(); return nil })
+
+	server := routes.NewServer(":"+config.AppConfig.Port, manager.Context())
+	failed := make(chan error, 1)
+	go func() {
+		logrus.Info("Starting server on port " + config.AppConfig.Port)
+		if err := server.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+			failed <- err
+		}
+	}()
+	manager.OnStop("HTTP server", func(ctx context.Context) error {
+		if err := server.Shutdown(ctx); err != nil {
+			server.Close()
+			return err
+		}
+		return nil
+	})
+	return failed, nil
+}
@@ -0,0 +1,527 @@
+package constants
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Real-Dev-Squad/discord-service/dtos"
+	"github.com/bwmarrin/discordgo"
+	"gopkg.in/yaml.v3"
+)
+
+// CommandsFileVersion is the only version of the commands file understood.
+const CommandsFileVersion = 1
+
+// Discord's limits on application commands.
+const (
+	MaxCommands          = 100
+	MaxNameLength        = 32
+	MaxDescriptionLength = 100
+	MaxOptions           = 25
+	MaxChoices           = 25
+	MaxChoiceLength      = 100
+	MaxCommandLength     = 4000
+	MaxStringOptionLimit = 6000
+)
+
+// namePattern is the name Discord accepts for chat input commands and their
+// options. Letters must also be lowercase where the script has cases.
+var namePattern = regexp.MustCompile(`^[-_\p{L}\p{N}\p{Devanagari}\p{Thai}]{1,32}$`)
+
+// OptionTypes are the option types the commands file can use, by name.
+var OptionTypes = map[string]discordgo.ApplicationCommandOptionType{
+	"subcommand":      discordgo.ApplicationCommandOptionSubCommand,
+	"subcommandGroup": discordgo.ApplicationCommandOptionSubCommandGroup,
+	"string":          discordgo.ApplicationCommandOptionString,
+	"integer":         discordgo.ApplicationCommandOptionInteger,
+	"boolean":         discordgo.ApplicationCommandOptionBoolean,
+	"user":            discordgo.ApplicationCommandOptionUser,
+	"channel":         discordgo.ApplicationCommandOptionChannel,
+	"role":            discordgo.ApplicationCommandOptionRole,
+	"mentionable":     discordgo.ApplicationCommandOptionMentionable,
+	"number":          discordgo.ApplicationCommandOptionNumber,
+	"attachment":      discordgo.ApplicationCommandOptionAttachment,
+}
+
+// Permissions are the permissions the commands file can require, by name.
+var Permissions = map[string]int64{
+	"administrator":    discordgo.PermissionAdministrator,
+	"manage_guild":     discordgo.PermissionManageServer,
+	"manage_roles":     discordgo.PermissionManageRoles,
+	"manage_nicknames": discordgo.PermissionManageNicknames,
+	"manage_channels":  discordgo.PermissionManageChannels,
+	"manage_messages":  discordgo.PermissionManageMessages,
+	"manage_webhooks":  discordgo.PermissionManageWebhooks,
+	"kick_members":     discordgo.PermissionKickMembers,
+	"ban_members":      discordgo.PermissionBanMembers,
+	"moderate_members": discordgo.PermissionModerateMembers,
+	"view_audit_log":   discordgo.PermissionViewAuditLogs,
+	"mention_everyone": discordgo.PermissionMentionEveryone,
+	"send_messages":    discordgo.PermissionSendMessages,
+	"view_channel":     discordgo.PermissionViewChannel,
+}
+
+// CommandsFile is the versioned file slash commands are declared in.
+type CommandsFile struct {
+	Version  int           `yaml:"version"`
+	Commands []CommandSpec `yaml:"commands"`
+}
+
+// CommandSpec declares one slash command.
+type CommandSpec struct {
+	Name                     string            `yaml:"name"`
+	NameLocalizations        map[string]string `yaml:"nameLocalizations"`
+	Description              string            `yaml:"description"`
+	DescriptionLocalizations map[string]string `yaml:"descriptionLocalizations"`
+	Scope                    dtos.CommandScope `yaml:"scope"`
+	AllowDM                  bool              `yaml:"allowDM"`
+	AllowOtherGuilds         bool              `yaml:"allowOtherGuilds"`
+	DefaultMemberPermissions []string          `yaml:"defaultMemberPermissions"`
+	NSFW                     bool              `yaml:"nsfw"`
+	Cooldown                 *CooldownSpec     `yaml:"cooldown"`
+	Options                  []OptionSpec      `yaml:"options"`
+}
+
+// CooldownSpec limits a user to Burst invocations per Window, e.g. "1m".
+type CooldownSpec struct {
+	Window time.Duration `yaml:"window"`
+	Burst  int           `yaml:"burst"`
+}
+
+// OptionSpec declares an option, or a subcommand with its own options.
+type OptionSpec struct {
+	Name                     string            `yaml:"name"`
+	NameLocalizations        map[string]string `yaml:"nameLocalizations"`
+	Description              string            `yaml:"description"`
+	DescriptionLocalizations map[string]string `yaml:"descriptionLocalizations"`
+	Type                     string            `yaml:"type"`
+	Required                 bool              `yaml:"required"`
+	Autocomplete             bool              `yaml:"autocomplete"`
+	Choices                  []ChoiceSpec      `yaml:"choices"`
+	MinValue                 *float64          `yaml:"minValue"`
+	MaxValue                 *float64          `yaml:"maxValue"`
+	MinLength                *int              `yaml:"minLength"`
+	MaxLength                *int              `yaml:"maxLength"`
+	Options                  []OptionSpec      `yaml:"options"`
+}
+
+// ChoiceSpec is a fixed value a string, integer or number option can take.
+type ChoiceSpec struct {
+	Name              string            `yaml:"name"`
+	NameLocalizations map[string]string `yaml:"nameLocalizations"`
+	Value             any               `yaml:"value"`
+}
+
+// LoadCommands reads and validates the commands file at path.
+func LoadCommands(path string) ([]*dtos.CommandDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read commands file: %v", err)
+	}
+	commands, err := ParseCommands(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid commands file %s: %w", path, err)
+	}
+	return commands, nil
+}
+
+// ParseCommands decodes a commands file, in YAML or JSON, and validates it
+// against Discord's limits. Every problem found is reported, not just the first.
+func ParseCommands(data []byte) ([]*dtos.CommandDefinition, error) {
+	file := CommandsFile{}
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("cannot decode commands: %v", err)
+	}
+	if err := file.Validate(); err != nil {
+		return nil, err
+	}
+
+	commands := make([]*dtos.CommandDefinition, 0, len(file.Commands))
+	for _, spec := range file.Commands {
+		commands = append(commands, spec.definition())
+	}
+	return commands, nil
+}
+
+// Validate checks the file against Discord's limits and the scopes, cooldowns
+// and permissions the service understands.
+func (f CommandsFile) Validate() error {
+	v := &validator{}
+	if f.Version != CommandsFileVersion {
+		v.fail("version", "must be %d, got %d", CommandsFileVersion, f.Version)
+	}
+	if len(f.Commands) == 0 {
+		v.fail("commands", "at least one command is required")
+	}
+	if len(f.Commands) > MaxCommands {
+		v.fail("commands", "at most %d commands are allowed, got %d", MaxCommands, len(f.Commands))
+	}
+
+	names := map[string]bool{}
+	for i, command := range f.Commands {
+		path := fmt.Sprintf("commands[%d]", i)
+		if names[command.Name] {
+			v.fail(path+".name", "duplicate command %q", command.Name)
+		}
+		names[command.Name] = true
+		v.command(path, command)
+	}
+	return errors.Join(v.errs...)
+}
+
+type validator struct {
+	errs []error
+}
+
+func (v *validator) fail(path string, format string, args ...any) {
+	v.errs = append(v.errs, fmt.Errorf("%s: %s", path, fmt.Sprintf(format, args...)))
+}
+
+func (v *validator) command(path string, command CommandSpec) {
+	v.name(path+".name", command.Name)
+	v.localizedNames(path+".nameLocalizations", command.NameLocalizations)
+	v.description(path+".description", command.Description)
+	v.localizedDescriptions(path+".descriptionLocalizations", command.DescriptionLocalizations)
+
+	switch command.Scope {
+	case "", dtos.ScopeGuilds, dtos.ScopeGlobal, dtos.ScopeDevGuild:
+	default:
+		v.fail(path+".scope", "unknown scope %q, expected %s, %s or %s", command.Scope, dtos.ScopeGuilds, dtos.ScopeGlobal, dtos.ScopeDevGuild)
+	}
+	for _, permission := range command.DefaultMemberPermissions {
+		if _, ok := Permissions[permission]; !ok {
+			v.fail(path+".defaultMemberPermissions", "unknown permission %q", permission)
+		}
+	}
+	if command.Cooldown != nil && (command.Cooldown.Window <= 0 || command.Cooldown.Burst <= 0) {
+		v.fail(path+".cooldown", "window and burst must be positive")
+	}
+
+	v.options(path+".options", command.Options, 0)
+	if length := commandLength(command); length > MaxCommandLength {
+		v.fail(path, "names, descriptions and choices add up to %d characters, at most %d are allowed", length, MaxCommandLength)
+	}
+}
+
+// options validates a list of options, where depth is how many subcommand
+// levels they are nested in.
+func (v *validator) options(path string, options []OptionSpec, depth int) {
+	if len(options) > MaxOptions {
+		v.fail(path, "at most %d options are allowed, got %d", MaxOptions, len(options))
+	}
+
+	names := map[string]bool{}
+	subcommands, optional := 0, false
+	for i, option := range options {
+		optionPath := fmt.Sprintf("%s[%d]", path, i)
+		if names[option.Name] {
+			v.fail(optionPath+".name", "duplicate option %q", option.Name)
+		}
+		names[option.Name] = true
+
+		optionType, ok := OptionTypes[option.Type]
+		if !ok {
+			v.fail(optionPath+".type", "unknown option type %q", option.Type)
+		}
+		isSubcommand := optionType == discordgo.ApplicationCommandOptionSubCommand || optionType == discordgo.ApplicationCommandOptionSubCommandGroup
+		if isSubcommand {
+			subcommands++
+		}
+		if option.Required && optional && !isSubcommand {
+			v.fail(optionPath+".required", "required options must come before optional ones")
+		}
+		if !option.Required {
+			optional = true
+		}
+		v.option(optionPath, option, optionType, depth)
+	}
+	if subcommands > 0 && subcommands != len(options) {
+		v.fail(path, "subcommands cannot be mixed with other options")
+	}
+}
+
+func (v *validator) option(path string, option OptionSpec, optionType discordgo.ApplicationCommandOptionType, depth int) {
+	v.name(path+".name", option.Name)
+	v.localizedNames(path+".nameLocalizations", option.NameLocalizations)
+	v.description(path+".description", option.Description)
+	v.localizedDescriptions(path+".descriptionLocalizations", option.DescriptionLocalizations)
+
+	switch optionType {
+	case discordgo.ApplicationCommandOptionSubCommandGroup:
+		if depth > 0 {
+			v.fail(path+".type", "subcommand groups cannot be nested")
+		}
+		for i, sub := range option.Options {
+			if sub.Type != "subcommand" {
+				v.fail(fmt.Sprintf("%s.options[%d].type", path, i), "subcommand groups can only contain subcommands")
+			}
+		}
+		v.options(path+".options", option.Options, depth+1)
+	case discordgo.ApplicationCommandOptionSubCommand:
+		for i, sub := range option.Options {
+			if sub.Type == "subcommand" || sub.Type == "subcommandGroup" {
+				v.fail(fmt.Sprintf("%s.options[%d].type", path, i), "subcommands cannot contain subcommands")
+			}
+		}
+		v.options(path+".options", option.Options, depth+1)
+	default:
+		if len(option.Options) > 0 {
+			v.fail(path+".options", "only subcommands can have options")
+		}
+	}
+	if option.Required && (optionType == discordgo.ApplicationCommandOptionSubCommand || optionType == discordgo.ApplicationCommandOptionSubCommandGroup) {
+		v.fail(path+".required", "subcommands cannot be required")
+	}
+
+	numeric := optionType == discordgo.ApplicationCommandOptionInteger || optionType == discordgo.ApplicationCommandOptionNumber
+	text := optionType == discordgo.ApplicationCommandOptionString
+	if (option.MinValue != nil || option.MaxValue != nil) && !numeric {
+		v.fail(path, "minValue and maxValue are only allowed on integer and number options")
+	}
+	if option.MinValue != nil && option.MaxValue != nil && *option.MinValue > *option.MaxValue {
+		v.fail(path, "minValue is greater than maxValue")
+	}
+	if (option.MinLength != nil || option.MaxLength != nil) && !text {
+		v.fail(path, "minLength and maxLength are only allowed on string options")
+	}
+	if option.MinLength != nil && (*option.MinLength < 0 || *option.MinLength > MaxStringOptionLimit) {
+		v.fail(path+".minLength", "must be between 0 and %d", MaxStringOptionLimit)
+	}
+	if option.MaxLength != nil && (*option.MaxLength < 1 || *option.MaxLength > MaxStringOptionLimit) {
+		v.fail(path+".maxLength", "must be between 1 and %d", MaxStringOptionLimit)
+	}
+
+	if len(option.Choices) == 0 {
+		return
+	}
+	if !numeric && !text {
+		v.fail(path+".choices", "only string, integer and number options can have choices")
+		return
+	}
+	if option.Autocomplete {
+		v.fail(path+".autocomplete", "cannot be combined with choices")
+	}
+	if len(option.Choices) > MaxChoices {
+		v.fail(path+".choices", "at most %d choices are allowed, got %d", MaxChoices, len(option.Choices))
+	}
+	for i, choice := range option.Choices {
+		choicePath := fmt.Sprintf("%s.choices[%d]", path, i)
+		v.length(choicePath+".name", choice.Name, 1, MaxChoiceLength)
+		v.localizations(choicePath+".nameLocalizations", choice.NameLocalizations, func(path, value string) {
+			v.length(path, value, 1, MaxChoiceLength)
+		})
+		if _, err := choiceValue(choice.Value, optionType); err != nil {
+			v.fail(choicePath+".value", "%v", err)
+		}
+	}
+}
+
+func (v *validator) name(path string, name string) {
+	if !namePattern.MatchString(name) {
+		v.fail(path, "%q must be 1-%d letters, numbers, - or _", name, MaxNameLength)
+	} else if strings.ToLower(name) != name {
+		v.fail(path, "%q must be lowercase", name)
+	}
+}
+
+func (v *validator) description(path string, description string) {
+	v.length(path, description, 1, MaxDescriptionLength)
+}
+
+func (v *validator) length(path string, value string, min int, max int) {
+	if length := utf8.RuneCountInString(value); length < min || length > max {
+		v.fail(path, "must be %d-%d characters, got %d", min, max, length)
+	}
+}
+
+func (v *validator) localizedNames(path string, localizations map[string]string) {
+	v.localizations(path, localizations, v.name)
+}
+
+func (v *validator) localizedDescriptions(path string, localizations map[string]string) {
+	v.localizations(path, localizations, v.description)
+}
+
+// localizations checks every locale is one Discord supports and validates each
+// localized value with check, in the order of the locales.
+func (v *validator) localizations(path string, localizations map[string]string, check func(path, value string)) {
+	locales := make([]string, 0, len(localizations))
+	for locale := range localizations {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+	for _, locale := range locales {
+		if _, ok := discordgo.Locales[discordgo.Locale(locale)]; !ok {
+			v.fail(path, "unknown locale %q", locale)
+			continue
+		}
+		check(path+"."+locale, localizations[locale])
+	}
+}
+
+// choiceValue converts a decoded choice value to what Discord expects for an
+// option of optionType.
+func choiceValue(value any, optionType discordgo.ApplicationCommandOptionType) (any, error) {
+	switch optionType {
+	case discordgo.ApplicationCommandOptionString:
+		text, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("must be a string, got %v", value)
+		}
+		if utf8.RuneCountInString(text) > MaxChoiceLength {
+			return nil, fmt.Errorf("must be at most %d characters", MaxChoiceLength)
+		}
+		return text, nil
+	case discordgo.ApplicationCommandOptionInteger:
+		number, ok := value.(int)
+		if !ok {
+			return nil, fmt.Errorf("must be an integer, got %v", value)
+		}
+		return number, nil
+	default:
+		switch number := value.(type) {
+		case int:
+			return float64(number), nil
+		case float64:
+			return number, nil
+		}
+		return nil, fmt.Errorf("must be a number, got %v", value)
+	}
+}
+
+// commandLength is what Discord counts against MaxCommandLength: every name,
+// description and choice, taking the longest of a value and its localizations.
+func commandLength(command CommandSpec) int {
+	return longest(command.Name, command.NameLocalizations) +
+		longest(command.Description, command.DescriptionLocalizations) +
+		optionsLength(command.Options)
+}
+
+func optionsLength(options []OptionSpec) int {
+	length := 0
+	for _, option := range options {
+		length += longest(option.Name, option.NameLocalizations) +
+			longest(option.Description, option.DescriptionLocalizations) +
+			optionsLength(option.Options)
+		for _, choice := range option.Choices {
+			length += longest(choice.Name, choice.NameLocalizations) + utf8.RuneCountInString(fmt.Sprint(choice.Value))
+		}
+	}
+	return length
+}
+
+func longest(value string, localizations map[string]string) int {
+	length := utf8.RuneCountInString(value)
+	for _, localized := range localizations {
+		length = max(length, utf8.RuneCountInString(localized))
+	}
+	return length
+}
+
+// definition converts a validated spec to the definition used for registration
+// and dispatch.
+func (s CommandSpec) definition() *dtos.CommandDefinition {
+	command := &discordgo.ApplicationCommand{
+		Name:                     s.Name,
+		NameLocalizations:        localizationsPointer(s.NameLocalizations),
+		Description:              s.Description,
+		DescriptionLocalizations: localizationsPointer(s.DescriptionLocalizations),
+		Options:                  optionDefinitions(s.Options),
+	}
+	if s.DefaultMemberPermissions != nil {
+		permissions := int64(0)
+		for _, permission := range s.DefaultMemberPermissions {
+			permissions |= Permissions[permission]
+		}
+		command.DefaultMemberPermissions = &permissions
+	}
+	if s.NSFW {
+		nsfw := true
+		command.NSFW = &nsfw
+	}
+	scope := s.Scope
+	if scope == "" {
+		scope = dtos.ScopeGuilds
+	}
+	if scope == dtos.ScopeGlobal {
+		// Discord only honours the DM permission of global commands.
+		allowDM := s.AllowDM
+		command.DMPermission = &allowDM
+	}
+
+	definition := &dtos.CommandDefinition{
+		ApplicationCommand: command,
+		Scope:              scope,
+		AllowDM:            s.AllowDM,
+		AllowOtherGuilds:   s.AllowOtherGuilds,
+	}
+	if s.Cooldown != nil {
+		definition.Cooldown = &dtos.Cooldown{Window: s.Cooldown.Window, Burst: s.Cooldown.Burst}
+	}
+	return definition
+}
+
+func optionDefinitions(specs []OptionSpec) []*discordgo.ApplicationCommandOption {
+	if len(specs) == 0 {
+		return nil
+	}
+	options := make([]*discordgo.ApplicationCommandOption, 0, len(specs))
+	for _, spec := range specs {
+		option := &discordgo.ApplicationCommandOption{
+			Type:                     OptionTypes[spec.Type],
+			Name:                     spec.Name,
+			NameLocalizations:        localizations(spec.NameLocalizations),
+			Description:              spec.Description,
+			DescriptionLocalizations: localizations(spec.DescriptionLocalizations),
+			Required:                 spec.Required,
+			Autocomplete:             spec.Autocomplete,
+			MinValue:                 spec.MinValue,
+			MinLength:                spec.MinLength,
+			Options:                  optionDefinitions(spec.Options),
+		}
+		if spec.MaxValue != nil {
+			option.MaxValue = *spec.MaxValue
+		}
+		if spec.MaxLength != nil {
+			option.MaxLength = *spec.MaxLength
+		}
+		for _, choice := range spec.Choices {
+			value, _ := choiceValue(choice.Value, option.Type)
+			option.Choices = append(option.Choices, &discordgo.ApplicationCommandOptionChoice{
+				Name:              choice.Name,
+				NameLocalizations: localizations(choice.NameLocalizations),
+				Value:             value,
+			})
+		}
+		options = append(options, option)
+	}
+	return options
+}
+
+func localizations(values map[string]string) map[discordgo.Locale]string {
+	if len(values) == 0 {
+		return nil
+	}
+	result := make(map[discordgo.Locale]string, len(values))
+	for locale, value := range values {
+		result[discordgo.Locale(locale)] = value
+	}
+	return result
+}
+
+func localizationsPointer(values map[string]string) *map[discordgo.Locale]string {
+	result := localizations(values)
+	if result == nil {
+		return nil
+	}
+	return &result
+}
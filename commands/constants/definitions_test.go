@@ -0,0 +1,246 @@
+package constants
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Real-Dev-Squad/discord-service/dtos"
+	"github.com/Real-Dev-Squad/discord-service/i18n"
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCommands(t *testing.T) {
+	t.Run("should load the built-in commands file", func(t *testing.T) {
+		commands, err := ParseCommands(commandsFile)
+		assert.NoError(t, err)
+		assert.Len(t, commands, 3)
+
+		listening := commands[1]
+		assert.Equal(t, discordgo.ApplicationCommandOptionBoolean, listening.Options[0].Type)
+		assert.True(t, listening.Options[0].Required)
+		assert.Equal(t, dtos.ScopeGuilds, listening.Scope)
+		assert.Equal(t, &dtos.Cooldown{Window: time.Minute, Burst: 2}, listening.Cooldown)
+
+		hello := commands[0]
+		assert.Equal(t, dtos.ScopeGlobal, hello.Scope)
+		assert.True(t, *hello.DMPermission)
+	})
+
+	t.Run("should localize the built-in commands into every locale of the message catalog", func(t *testing.T) {
+		commands, err := ParseCommands(commandsFile)
+		assert.NoError(t, err)
+		for _, locale := range i18n.Locales() {
+			if locale == i18n.DefaultLocale {
+				continue
+			}
+			for _, command := range commands {
+				assert.NotEmpty(t, (*command.NameLocalizations)[locale], "name of %s in %s", command.Name, locale)
+				assert.NotEmpty(t, (*command.DescriptionLocalizations)[locale], "description of %s in %s", command.Name, locale)
+				for _, option := range command.Options {
+					assert.NotEmpty(t, option.DescriptionLocalizations[locale], "description of %s %s in %s", command.Name, option.Name, locale)
+				}
+			}
+		}
+	})
+
+	t.Run("should decode every declared field", func(t *testing.T) {
+		commands, err := ParseCommands([]byte(`
+version: 1
+commands:
+  - name: standup
+    description: Post a standup
+    nameLocalizations: {hi: स्टैंडअप}
+    descriptionLocalizations: {en-GB: Post a stand-up}
+    scope: dev
+    defaultMemberPermissions: [manage_roles, moderate_members]
+    options:
+      - name: mood
+        description: How the day went
+        type: string
+        required: true
+        choices:
+          - {name: Good, value: good, nameLocalizations: {fr: Bien}}
+          - {name: Bad, value: bad}
+      - name: hours
+        description: Hours worked
+        type: number
+        minValue: 0
+        maxValue: 24
+`))
+		assert.NoError(t, err)
+		command := commands[0]
+		assert.Equal(t, dtos.ScopeDevGuild, command.Scope)
+		assert.Equal(t, "स्टैंडअप", (*command.NameLocalizations)[discordgo.Hindi])
+		assert.Equal(t, "Post a stand-up", (*command.DescriptionLocalizations)[discordgo.EnglishGB])
+		assert.Equal(t, int64(discordgo.PermissionManageRoles|discordgo.PermissionModerateMembers), *command.DefaultMemberPermissions)
+		assert.Nil(t, command.DMPermission)
+
+		mood := command.Options[0]
+		assert.Equal(t, discordgo.ApplicationCommandOptionString, mood.Type)
+		assert.Equal(t, "good", mood.Choices[0].Value)
+		assert.Equal(t, "Bien", mood.Choices[0].NameLocalizations[discordgo.French])
+
+		hours := command.Options[1]
+		assert.Equal(t, 0.0, *hours.MinValue)
+		assert.Equal(t, 24.0, hours.MaxValue)
+	})
+
+	t.Run("should decode JSON", func(t *testing.T) {
+		commands, err := ParseCommands([]byte(`{"version": 1, "commands": [{"name": "ping", "description": "Pong", "cooldown": {"window": "30s", "burst": 1}}]}`))
+		assert.NoError(t, err)
+		assert.Equal(t, "ping", commands[0].Name)
+		assert.Equal(t, 30*time.Second, commands[0].Cooldown.Window)
+	})
+
+	t.Run("should reject unknown fields", func(t *testing.T) {
+		_, err := ParseCommands([]byte("version: 1\ncommands:\n  - name: ping\n    descripton: Pong\n"))
+		assert.ErrorContains(t, err, "descripton")
+	})
+
+	t.Run("should require administrators when no permission is listed", func(t *testing.T) {
+		commands, err := ParseCommands([]byte("version: 1\ncommands:\n  - name: ping\n    description: Pong\n    defaultMemberPermissions: []\n"))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(0), *commands[0].DefaultMemberPermissions)
+	})
+}
+
+func TestValidateCommands(t *testing.T) {
+	valid := func() CommandsFile {
+		return CommandsFile{
+			Version: CommandsFileVersion,
+			Commands: []CommandSpec{{
+				Name:        "ping",
+				Description: "Pong",
+				Options: []OptionSpec{
+					{Name: "target", Description: "Who to ping", Type: "user", Required: true},
+				},
+			}},
+		}
+	}
+	options := func(count int) []OptionSpec {
+		result := []OptionSpec{}
+		for i := 0; i < count; i++ {
+			result = append(result, OptionSpec{Name: "option" + string(rune('a'+i)), Description: "An option", Type: "string"})
+		}
+		return result
+	}
+
+	tests := []struct {
+		name   string
+		change func(file *CommandsFile)
+		err    string
+	}{
+		{"unsupported version", func(f *CommandsFile) { f.Version = 2 }, "version: must be 1"},
+		{"no commands", func(f *CommandsFile) { f.Commands = nil }, "at least one command"},
+		{"invalid name", func(f *CommandsFile) { f.Commands[0].Name = "two words" }, "commands[0].name"},
+		{"uppercase name", func(f *CommandsFile) { f.Commands[0].Name = "Ping" }, "must be lowercase"},
+		{"long name", func(f *CommandsFile) { f.Commands[0].Name = strings.Repeat("a", 33) }, "commands[0].name"},
+		{"duplicate name", func(f *CommandsFile) { f.Commands = append(f.Commands, f.Commands[0]) }, `duplicate command "ping"`},
+		{"missing description", func(f *CommandsFile) { f.Commands[0].Description = "" }, "commands[0].description: must be 1-100 characters"},
+		{"long description", func(f *CommandsFile) { f.Commands[0].Description = strings.Repeat("é", 101) }, "got 101"},
+		{"unknown scope", func(f *CommandsFile) { f.Commands[0].Scope = "everywhere" }, `unknown scope "everywhere"`},
+		{"unknown permission", func(f *CommandsFile) { f.Commands[0].DefaultMemberPermissions = []string{"fly"} }, `unknown permission "fly"`},
+		{"invalid cooldown", func(f *CommandsFile) { f.Commands[0].Cooldown = &CooldownSpec{Window: time.Minute} }, "window and burst must be positive"},
+		{"unknown locale", func(f *CommandsFile) { f.Commands[0].NameLocalizations = map[string]string{"xx": "ping"} }, `unknown locale "xx"`},
+		{"invalid localized name", func(f *CommandsFile) { f.Commands[0].NameLocalizations = map[string]string{"fr": "Ping"} }, "nameLocalizations.fr"},
+		{"too many options", func(f *CommandsFile) { f.Commands[0].Options = options(26) }, "at most 25 options"},
+		{"unknown option type", func(f *CommandsFile) { f.Commands[0].Options[0].Type = "colour" }, `unknown option type "colour"`},
+		{"required after optional", func(f *CommandsFile) {
+			f.Commands[0].Options = append(options(1), f.Commands[0].Options...)
+		}, "required options must come before optional ones"},
+		{"choices on a boolean", func(f *CommandsFile) {
+			f.Commands[0].Options[0].Type = "boolean"
+			f.Commands[0].Options[0].Choices = []ChoiceSpec{{Name: "Yes", Value: true}}
+		}, "only string, integer and number options can have choices"},
+		{"choice of the wrong type", func(f *CommandsFile) {
+			f.Commands[0].Options[0].Type = "integer"
+			f.Commands[0].Options[0].Choices = []ChoiceSpec{{Name: "One", Value: "1"}}
+		}, "must be an integer"},
+		{"choices with autocomplete", func(f *CommandsFile) {
+			f.Commands[0].Options[0].Type = "string"
+			f.Commands[0].Options[0].Autocomplete = true
+			f.Commands[0].Options[0].Choices = []ChoiceSpec{{Name: "One", Value: "1"}}
+		}, "cannot be combined with choices"},
+		{"length on a number", func(f *CommandsFile) {
+			maxLength := 10
+			f.Commands[0].Options[0].Type = "number"
+			f.Commands[0].Options[0].MaxLength = &maxLength
+		}, "only allowed on string options"},
+		{"options on a plain option", func(f *CommandsFile) { f.Commands[0].Options[0].Options = options(1) }, "only subcommands can have options"},
+		{"subcommands mixed with options", func(f *CommandsFile) {
+			f.Commands[0].Options = append(f.Commands[0].Options, OptionSpec{Name: "list", Description: "List", Type: "subcommand"})
+		}, "subcommands cannot be mixed"},
+		{"nested subcommands", func(f *CommandsFile) {
+			f.Commands[0].Options = []OptionSpec{{Name: "list", Description: "List", Type: "subcommand", Options: []OptionSpec{
+				{Name: "all", Description: "All", Type: "subcommand"},
+			}}}
+		}, "subcommands cannot contain subcommands"},
+		{"too long altogether", func(f *CommandsFile) {
+			long := options(25)
+			for i := range long {
+				long[i].Description = strings.Repeat("d", 100)
+				long[i].Type = "string"
+				for c := 0; c < 25; c++ {
+					long[i].Choices = append(long[i].Choices, ChoiceSpec{Name: strings.Repeat("c", 10) + string(rune('a'+c)), Value: "v"})
+				}
+			}
+			f.Commands[0].Options = long
+		}, "at most 4000 are allowed"},
+	}
+	for _, test := range tests {
+		t.Run("should reject "+test.name, func(t *testing.T) {
+			file := valid()
+			test.change(&file)
+			assert.ErrorContains(t, file.Validate(), test.err)
+		})
+	}
+
+	t.Run("should accept a valid file", func(t *testing.T) {
+		assert.NoError(t, valid().Validate())
+	})
+
+	t.Run("should accept subcommand groups", func(t *testing.T) {
+		file := valid()
+		file.Commands[0].Options = []OptionSpec{{Name: "roles", Description: "Roles", Type: "subcommandGroup", Options: []OptionSpec{
+			{Name: "add", Description: "Add a role", Type: "subcommand", Options: []OptionSpec{
+				{Name: "role", Description: "The role", Type: "role", Required: true},
+			}},
+		}}}
+		assert.NoError(t, file.Validate())
+	})
+
+	t.Run("should report every problem", func(t *testing.T) {
+		file := valid()
+		file.Commands[0].Name = "Ping"
+		file.Commands[0].Description = ""
+		err := file.Validate()
+		assert.ErrorContains(t, err, "commands[0].name")
+		assert.ErrorContains(t, err, "commands[0].description")
+	})
+}
+
+func TestLoadCommands(t *testing.T) {
+	t.Run("should load a commands file from disk", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "commands.json")
+		assert.NoError(t, os.WriteFile(path, []byte(`{"version": 1, "commands": [{"name": "ping", "description": "Pong"}]}`), 0o600))
+		commands, err := LoadCommands(path)
+		assert.NoError(t, err)
+		assert.Equal(t, "ping", commands[0].Name)
+	})
+
+	t.Run("should name the file when it is invalid", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "commands.yaml")
+		assert.NoError(t, os.WriteFile(path, []byte("version: 2\n"), 0o600))
+		_, err := LoadCommands(path)
+		assert.ErrorContains(t, err, path)
+	})
+
+	t.Run("should return error when the file is missing", func(t *testing.T) {
+		_, err := LoadCommands(filepath.Join(t.TempDir(), "missing.yaml"))
+		assert.Error(t, err)
+	})
+}
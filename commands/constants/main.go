@@ -1,37 +1,68 @@
 package constants
 
 import (
-	"github.com/Real-Dev-Squad/discord-service/utils"
-	"github.com/bwmarrin/discordgo"
+	_ "embed"
+	"fmt"
+
+	"github.com/Real-Dev-Squad/discord-service/config"
+	"github.com/Real-Dev-Squad/discord-service/dtos"
+	"github.com/sirupsen/logrus"
 )
 
-var Commands = []*discordgo.ApplicationCommand{
-	{
-		Name:        utils.CommandNames.Hello,
-		Description: "Greets back with hello!",
-	},
-	{
-		Name:        utils.CommandNames.Listening,
-		Description: "Mark user as listening",
-		Options: []*discordgo.ApplicationCommandOption{
-			{
-				Name:        "value",
-				Description: "to enable or disable the listening mode",
-				Type:        5,
-				Required:    true,
-			},
-		},
-	},
-	{
-		Name:        utils.CommandNames.Verify,
-		Description: "Generate a link with user specific token to link with RDS backend",
-		Options: []*discordgo.ApplicationCommandOption{
-			{
-				Name:        "dev",
-				Description: "Use new website for verification.",
-				Type:        discordgo.ApplicationCommandOptionBoolean,
-				Required:    false,
-			},
-		},
-	},
+//go:embed commands.yaml
+var commandsFile []byte
+
+// Commands are the slash commands declared in commands.yaml, or in
+// COMMANDS_FILE once it is loaded at startup. They are used for both
+// registration and dispatch.
+var Commands = mustParseCommands(commandsFile)
+
+// mustParseCommands parses the built-in commands file, which the tests keep
+// valid.
+func mustParseCommands(data []byte) []*dtos.CommandDefinition {
+	commands, err := ParseCommands(data)
+	if err != nil {
+		logrus.Panic(fmt.Sprintf("Cannot load slash commands: %v", err))
+	}
+	return commands
+}
+
+// Targets returns the guilds a command is registered in, where "" stands for
+// a global registration.
+func Targets(command *dtos.CommandDefinition) []string {
+	switch command.Scope {
+	case dtos.ScopeGlobal:
+		if config.AppConfig.REGISTRATION_SCOPE == config.RegisterGuilds {
+			return config.AppConfig.COMMAND_GUILD_IDS
+		}
+		return []string{""}
+	case dtos.ScopeDevGuild:
+		if config.AppConfig.DEV_GUILD_ID == "" || config.AppConfig.REGISTRATION_SCOPE == config.RegisterPublic {
+			return []string{}
+		}
+		return []string{config.AppConfig.DEV_GUILD_ID}
+	default:
+		return config.AppConfig.COMMAND_GUILD_IDS
+	}
+}
+
+// RegisteredIn reports whether command is registered in guildID itself. A
+// global registration does not count, AllowOtherGuilds decides for those.
+func RegisteredIn(command *dtos.CommandDefinition, guildID string) bool {
+	for _, target := range Targets(command) {
+		if target != "" && target == guildID {
+			return true
+		}
+	}
+	return false
+}
+
+// FindCommand returns the definition registered under name, or nil if there is none.
+func FindCommand(name string) *dtos.CommandDefinition {
+	for _, command := range Commands {
+		if command.Name == name {
+			return command
+		}
+	}
+	return nil
 }
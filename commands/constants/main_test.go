@@ -3,6 +3,9 @@ package constants
 import (
 	"testing"
 
+	"github.com/Real-Dev-Squad/discord-service/config"
+	"github.com/Real-Dev-Squad/discord-service/dtos"
+	_ "github.com/Real-Dev-Squad/discord-service/tests/helpers"
 	"github.com/bwmarrin/discordgo"
 	"github.com/stretchr/testify/assert"
 )
@@ -34,4 +37,75 @@ func TestCommandsDefinition(t *testing.T) {
 		assert.Equal(t, "hello", helloCommand.Name)
 		assert.Equal(t, "Greets back with hello!", helloCommand.Description)
 	})
-}
\ No newline at end of file
+}
+func TestFindCommand(t *testing.T) {
+	t.Run("should return the definition for a known command", func(t *testing.T) {
+		command := FindCommand("verify")
+		assert.NotNil(t, command)
+		assert.Equal(t, "verify", command.Name)
+		assert.False(t, command.AllowDM)
+		assert.False(t, command.AllowOtherGuilds)
+	})
+
+	t.Run("should allow hello from direct messages and other guilds", func(t *testing.T) {
+		command := FindCommand("hello")
+		assert.NotNil(t, command)
+		assert.True(t, command.AllowDM)
+		assert.True(t, command.AllowOtherGuilds)
+	})
+
+	t.Run("should return nil for an unknown command", func(t *testing.T) {
+		assert.Nil(t, FindCommand("unknown"))
+	})
+}
+
+func TestTargets(t *testing.T) {
+	originalGuilds := config.AppConfig.COMMAND_GUILD_IDS
+	originalDevGuild := config.AppConfig.DEV_GUILD_ID
+	defer func() {
+		config.AppConfig.COMMAND_GUILD_IDS = originalGuilds
+		config.AppConfig.DEV_GUILD_ID = originalDevGuild
+	}()
+	config.AppConfig.COMMAND_GUILD_IDS = []string{"guild-1", "guild-2"}
+	config.AppConfig.DEV_GUILD_ID = ""
+
+	t.Run("should register global commands without a guild", func(t *testing.T) {
+		command := &dtos.CommandDefinition{Scope: dtos.ScopeGlobal}
+		assert.Equal(t, []string{""}, Targets(command))
+		assert.False(t, RegisteredIn(command, "guild-1"))
+	})
+
+	t.Run("should register guild commands in every command guild by default", func(t *testing.T) {
+		command := &dtos.CommandDefinition{}
+		assert.Equal(t, []string{"guild-1", "guild-2"}, Targets(command))
+		assert.True(t, RegisteredIn(command, "guild-2"))
+		assert.False(t, RegisteredIn(command, "guild-3"))
+	})
+
+	t.Run("should register dev commands only when the dev guild is set", func(t *testing.T) {
+		command := &dtos.CommandDefinition{Scope: dtos.ScopeDevGuild}
+		assert.Empty(t, Targets(command))
+		config.AppConfig.DEV_GUILD_ID = "dev-guild"
+		assert.Equal(t, []string{"dev-guild"}, Targets(command))
+		assert.True(t, RegisteredIn(command, "dev-guild"))
+		assert.False(t, RegisteredIn(command, "guild-1"))
+	})
+
+	t.Run("should register global commands in the guilds when registering in guilds only", func(t *testing.T) {
+		originalScope := config.AppConfig.REGISTRATION_SCOPE
+		defer func() { config.AppConfig.REGISTRATION_SCOPE = originalScope }()
+		config.AppConfig.REGISTRATION_SCOPE = config.RegisterGuilds
+		command := &dtos.CommandDefinition{Scope: dtos.ScopeGlobal}
+		assert.Equal(t, []string{"guild-1", "guild-2"}, Targets(command))
+		assert.True(t, RegisteredIn(command, "guild-1"))
+	})
+
+	t.Run("should skip dev commands when registering public commands", func(t *testing.T) {
+		originalScope := config.AppConfig.REGISTRATION_SCOPE
+		defer func() { config.AppConfig.REGISTRATION_SCOPE = originalScope }()
+		config.AppConfig.REGISTRATION_SCOPE = config.RegisterPublic
+		config.AppConfig.DEV_GUILD_ID = "dev-guild"
+		assert.Empty(t, Targets(&dtos.CommandDefinition{Scope: dtos.ScopeDevGuild}))
+		assert.Equal(t, []string{""}, Targets(&dtos.CommandDefinition{Scope: dtos.ScopeGlobal}))
+	})
+}
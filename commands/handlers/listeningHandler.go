@@ -2,18 +2,65 @@ package handlers
 
 import (
 	"fmt"
-	"strings"
 
 	"github.com/Real-Dev-Squad/discord-service/utils"
+	"github.com/bwmarrin/discordgo"
+	"github.com/sirupsen/logrus"
 )
 
+// displayName is the name Discord shows for member: their nickname, else their
+// global name, else their username.
+func displayName(member *discordgo.Member) string {
+	if member.Nick != "" {
+		return member.Nick
+	}
+	if member.User == nil {
+		return ""
+	}
+	if member.User.GlobalName != "" {
+		return member.User.GlobalName
+	}
+	return member.User.Username
+}
+
+// listeningNickname works out the nickname member should have with listening
+// mode on or off, from their current state on Discord. Other decorations are kept.
+func listeningNickname(member *discordgo.Member, listening bool) string {
+	nickName := utils.SetDecoration(displayName(member), utils.ListeningDecoration, listening)
+	if member.User != nil && (nickName == member.User.GlobalName || nickName == member.User.Username) {
+		// The member had no nickname of their own before listening, so clear it
+		// and let Discord show their name again.
+		return ""
+	}
+	return nickName
+}
+
 func (s *CommandHandler) listeningHandler() error {
 	metaData := s.discordMessage.MetaData
-	nickName := metaData["nickname"]
-	if metaData["value"] == "true" {
-		nickName = fmt.Sprintf("%s%s%s", utils.NICKNAME_PREFIX, nickName, utils.NICKNAME_SUFFIX)
-	} else {
-		nickName = strings.TrimPrefix(strings.TrimSuffix(nickName, utils.NICKNAME_SUFFIX), utils.NICKNAME_PREFIX)
+	session, err := s.discordClient()
+	if err != nil {
+		return err
+	}
+	member, err := session.GuildMember(s.guildID(), s.discordMessage.UserID)
+	if err != nil {
+		return fmt.Errorf("error fetching guild member %s: %v", s.discordMessage.UserID, err)
+	}
+
+	listening := metaData["value"] == "true"
+	nickName := listeningNickname(member, listening)
+	if nickName == member.Nick {
+		logrus.Infof("Nickname of %s already matches listening mode", s.discordMessage.UserID)
+		return nil
+	}
+
+	mode := "disabled"
+	if listening {
+		mode = "enabled"
+	}
+	invokedBy := metaData["userName"]
+	if invokedBy == "" {
+		invokedBy = s.discordMessage.UserID
 	}
-	return UpdateNickName(s.discordMessage.UserID, nickName)
+	reason := fmt.Sprintf("listening mode %s via /listening by %s", mode, invokedBy)
+	return s.UpdateNickName(s.discordMessage.UserID, nickName, reason)
 }
@@ -6,58 +6,89 @@ import (
 	"github.com/Real-Dev-Squad/discord-service/dtos"
 	_ "github.com/Real-Dev-Squad/discord-service/tests/helpers"
 	"github.com/Real-Dev-Squad/discord-service/utils"
+	"github.com/bwmarrin/discordgo"
 	"github.com/stretchr/testify/assert"
 )
 
-type MockCommandHandler struct {
-	discordMessage *dtos.DataPacket
+func TestListeningNickname(t *testing.T) {
+	user := &discordgo.User{ID: "1", Username: "joy_gupta", GlobalName: "Joy Gupta"}
+	tests := []struct {
+		name      string
+		member    *discordgo.Member
+		listening bool
+		want      string
+	}{
+		{"should decorate the nickname", &discordgo.Member{User: user, Nick: "joy"}, true, utils.NICKNAME_PREFIX + "joy" + utils.NICKNAME_SUFFIX},
+		{"should fall back to the global name without a nickname", &discordgo.Member{User: user}, true, utils.NICKNAME_PREFIX + "Joy Gupta" + utils.NICKNAME_SUFFIX},
+		{"should fall back to the username without a global name", &discordgo.Member{User: &discordgo.User{Username: "joy_gupta"}}, true, utils.NICKNAME_PREFIX + "joy_gupta" + utils.NICKNAME_SUFFIX},
+		{"should keep a decorated nickname decorated once", &discordgo.Member{User: user, Nick: utils.NICKNAME_PREFIX + "joy" + utils.NICKNAME_SUFFIX}, true, utils.NICKNAME_PREFIX + "joy" + utils.NICKNAME_SUFFIX},
+		{"should remove the decoration", &discordgo.Member{User: user, Nick: utils.NICKNAME_PREFIX + "joy" + utils.NICKNAME_SUFFIX}, false, "joy"},
+		{"should keep other decorations", &discordgo.Member{User: user, Nick: utils.FormatNickname("joy", utils.OnCallDecoration)}, true, utils.FormatNickname("joy", utils.OnCallDecoration, utils.ListeningDecoration)},
+		{"should shorten a long name to fit the decoration", &discordgo.Member{User: user, Nick: "a nickname that is much too long"}, true, utils.NICKNAME_PREFIX + "a nickname that is" + utils.NICKNAME_SUFFIX},
+		{"should clear a nickname that was only the decorated global name", &discordgo.Member{User: user, Nick: utils.NICKNAME_PREFIX + "Joy Gupta" + utils.NICKNAME_SUFFIX}, false, ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, listeningNickname(test.member, test.listening))
+		})
+	}
 }
 
 func TestListeningHandler(t *testing.T) {
+	newSession := func(nick string) *mockMembersSession {
+		return &mockMembersSession{members: []*discordgo.Member{
+			{User: &discordgo.User{ID: "userID", Username: "joy_gupta"}, Nick: nick},
+		}}
+	}
 
-	t.Run("should update nickname with prefix and suffix if value is true", func(t *testing.T) {
-
+	t.Run("should decorate the live nickname when listening is turned on", func(t *testing.T) {
 		dataPacket := &dtos.DataPacket{
-			UserID: "userID",
-			MetaData: map[string]string{
-				"nickname": "testNick",
-				"value":    "true",
-			},
+			UserID:   "userID",
+			MetaData: map[string]string{"value": "true", "userName": "joy"},
 		}
-
-		handler := &CommandHandler{discordMessage: dataPacket}
+		session := newSession("renamed")
+		handler := &CommandHandler{discordMessage: dataPacket, discord: session}
 		err := handler.listeningHandler()
-		assert.Error(t, err)
+		assert.NoError(t, err)
+		assert.Equal(t, utils.NICKNAME_PREFIX+"renamed"+utils.NICKNAME_SUFFIX, *session.nickname)
+		assert.Equal(t, "listening mode enabled via /listening by joy", session.reason)
 	})
 
-	t.Run("should update nickname without prefix and suffix if value is false", func(t *testing.T) {
-
+	t.Run("should remove the decoration when listening is turned off", func(t *testing.T) {
 		dataPacket := &dtos.DataPacket{
-			UserID: "userID",
-			MetaData: map[string]string{
-				"nickname": utils.NICKNAME_PREFIX + "testNick" + utils.NICKNAME_SUFFIX,
-				"value":    "false",
-			},
+			UserID:   "userID",
+			MetaData: map[string]string{"value": "false"},
 		}
-
-		handler := &CommandHandler{discordMessage: dataPacket}
+		session := newSession(utils.NICKNAME_PREFIX + "testNick" + utils.NICKNAME_SUFFIX)
+		handler := &CommandHandler{discordMessage: dataPacket, discord: session}
 		err := handler.listeningHandler()
-		assert.Error(t, err)
+		assert.NoError(t, err)
+		assert.Equal(t, "testNick", *session.nickname)
+		assert.Equal(t, "listening mode disabled via /listening by userID", session.reason)
 	})
 
-	t.Run("should return error if UpdateNickName fails", func(t *testing.T) {
-
+	t.Run("should not rename a member already in the requested mode", func(t *testing.T) {
 		dataPacket := &dtos.DataPacket{
-			UserID: "userID",
-			MetaData: map[string]string{
-				"nickname": "testNick",
-				"value":    "true",
-			},
+			UserID:   "userID",
+			MetaData: map[string]string{"value": "true"},
 		}
+		session := newSession(utils.NICKNAME_PREFIX + "testNick" + utils.NICKNAME_SUFFIX)
+		handler := &CommandHandler{discordMessage: dataPacket, discord: session}
+		assert.NoError(t, handler.listeningHandler())
+		assert.Nil(t, session.nickname)
+	})
+
+	t.Run("should return error when the member cannot be fetched", func(t *testing.T) {
+		dataPacket := &dtos.DataPacket{UserID: "unknown", MetaData: map[string]string{"value": "true"}}
+		handler := &CommandHandler{discordMessage: dataPacket, discord: newSession("")}
+		assert.Error(t, handler.listeningHandler())
+	})
 
+	t.Run("should return error if the discord client is not initialized", func(t *testing.T) {
+		dataPacket := &dtos.DataPacket{UserID: "userID", MetaData: map[string]string{"value": "true"}}
 		handler := &CommandHandler{discordMessage: dataPacket}
 		err := handler.listeningHandler()
 		assert.Error(t, err)
-		assert.Equal(t, "websocket: close 4004: Authentication failed.", err.Error())
+		assert.Equal(t, ErrDiscordClientNotInitialized, err)
 	})
 }
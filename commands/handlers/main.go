@@ -2,10 +2,14 @@ package handlers
 
 import (
 	"errors"
+	"fmt"
+	"sync"
+	"unicode/utf8"
 
+	"github.com/Real-Dev-Squad/discord-service/audit"
 	"github.com/Real-Dev-Squad/discord-service/config"
+	"github.com/Real-Dev-Squad/discord-service/discord"
 	"github.com/Real-Dev-Squad/discord-service/dtos"
-	"github.com/Real-Dev-Squad/discord-service/models"
 	"github.com/Real-Dev-Squad/discord-service/utils"
 	"github.com/bwmarrin/discordgo"
 	"github.com/sirupsen/logrus"
@@ -13,10 +17,45 @@ import (
 
 type CommandHandler struct {
 	discordMessage *dtos.DataPacket
+	discord        DiscordSessionWrapper
 }
 
 var CS = CommandHandler{}
 
+// discordMu guards CS.discord, which is replaced when the bot token is reloaded.
+var discordMu sync.RWMutex
+
+var ErrDiscordClientNotInitialized = errors.New("discord client is not initialized")
+
+// SetDiscordClient injects the Discord client shared by every queued job and
+// returns the client it replaced, nil when there was none.
+func SetDiscordClient(client DiscordSessionWrapper) DiscordSessionWrapper {
+	discordMu.Lock()
+	defer discordMu.Unlock()
+	previous := CS.discord
+	CS.discord = client
+	return previous
+}
+
+// CloseDiscordClient closes the current Discord client, which is not the one
+// started with when the bot token was reloaded since.
+func CloseDiscordClient() error {
+	client, err := CS.discordClient()
+	if err != nil {
+		return nil
+	}
+	return client.Close()
+}
+
+func (s *CommandHandler) discordClient() (DiscordSessionWrapper, error) {
+	discordMu.RLock()
+	defer discordMu.RUnlock()
+	if s.discord == nil {
+		return nil, ErrDiscordClientNotInitialized
+	}
+	return s.discord, nil
+}
+
 func MainHandler(dataPacket []byte) func() error {
 	packetData := &dtos.DataPacket{}
 	err := packetData.FromByte(dataPacket)
@@ -30,6 +69,16 @@ func MainHandler(dataPacket []byte) func() error {
 		return CS.listeningHandler
 	case utils.CommandNames.Verify:
 		return CS.verify
+	case utils.CommandNames.MemberSync:
+		return CS.memberSync
+	case utils.CommandNames.RoleSync:
+		return CS.roleSync
+	case utils.CommandNames.VerifyComplete:
+		return CS.verifyComplete
+	case utils.CommandNames.NicknameReconcile:
+		return CS.nicknameReconcile
+	case utils.CommandNames.NicknameRepair:
+		return CS.nicknameRepair
 	default:
 		logrus.Warn("Invalid Command Received: ", packetData.CommandName)
 		return nil
@@ -39,41 +88,127 @@ func MainHandler(dataPacket []byte) func() error {
 type DiscordSessionWrapper interface {
 	WebhookMessageEdit(webhookID string, token string, messageID string, data *discordgo.WebhookEdit, options ...discordgo.RequestOption) (*discordgo.Message, error)
 	GuildMemberNickname(guildID string, userID string, nickname string, options ...discordgo.RequestOption) error
+	WebhookExecute(webhookID string, token string, wait bool, data *discordgo.WebhookParams, options ...discordgo.RequestOption) (*discordgo.Message, error)
+	GuildMember(guildID string, userID string, options ...discordgo.RequestOption) (*discordgo.Member, error)
+	GuildMembers(guildID string, after string, limit int, options ...discordgo.RequestOption) ([]*discordgo.Member, error)
+	GuildMemberRoleAdd(guildID string, userID string, roleID string, options ...discordgo.RequestOption) error
+	GuildMemberRoleRemove(guildID string, userID string, roleID string, options ...discordgo.RequestOption) error
+	UserChannelCreate(recipientID string, options ...discordgo.RequestOption) (*discordgo.Channel, error)
+	ChannelMessageSend(channelID string, content string, options ...discordgo.RequestOption) (*discordgo.Message, error)
 	Close() error
 }
-type DiscordSession struct {
-	session *discordgo.Session
-}
 
 var NewDiscord = discordgo.New
-var CreateSession = func() (DiscordSessionWrapper, error) {
-	session, err := NewDiscord("Bot " + config.AppConfig.BOT_TOKEN)
-	if err != nil {
-		logrus.Errorf("Cannot create a new Discord session: %v", err)
-		return nil, err
-	}
-	openSession := &models.SessionWrapper{Session: session}
-	err = openSession.Open()
+
+// NewDiscordClient creates a REST-only Discord client. It never opens a gateway
+// connection, so it is meant to be created once at startup and shared.
+func NewDiscordClient() (DiscordSessionWrapper, error) {
+	session, err := NewDiscord("Bot " + config.AppConfig.BOT_TOKEN.Value())
 	if err != nil {
-		logrus.Errorf("Cannot open the session: %v", err)
+		logrus.Errorf("Cannot create a new Discord client: %v", err)
 		return nil, err
 	}
+	// Rate limits are waited out by the transport, so only a limit that
+	// outlasts its retries reaches the caller as an error.
+	session.Client.Transport = discord.NewRateLimitTransport(session.Client.Transport)
+	session.Client.Timeout = config.AppConfig.DISCORD_REQUEST_TIMEOUT
+	session.ShouldRetryOnRateLimit = false
 	return session, nil
 }
 
-func UpdateNickName(userId string, newNickName string) error {
-	if len(newNickName) > 32 {
+// GuildMembersPageSize is the largest page Discord returns when listing members.
+const GuildMembersPageSize = 1000
+
+// forEachGuildMember pages through the members of the guild and calls fn for
+// every member that is not a bot.
+func forEachGuildMember(session DiscordSessionWrapper, fn func(member *discordgo.Member)) error {
+	after := ""
+	for {
+		members, err := session.GuildMembers(config.AppConfig.GUILD_ID, after, GuildMembersPageSize)
+		if err != nil {
+			return fmt.Errorf("error listing guild members: %v", err)
+		}
+		for _, member := range members {
+			if member.User == nil || member.User.Bot {
+				continue
+			}
+			fn(member)
+		}
+		if len(members) < GuildMembersPageSize {
+			return nil
+		}
+		after = members[len(members)-1].User.ID
+	}
+}
+
+// guildID is the guild the job acts on: the one the command was invoked from,
+// else GUILD_ID.
+func (s *CommandHandler) guildID() string {
+	if s.discordMessage != nil && s.discordMessage.GuildID != "" {
+		return s.discordMessage.GuildID
+	}
+	return config.AppConfig.GUILD_ID
+}
+
+// auditActor is who asked for a change: the invoking user for slash commands,
+// the job itself for everything else.
+func (s *CommandHandler) auditActor() string {
+	if s.discordMessage == nil {
+		return DiscordService
+	}
+	switch s.discordMessage.CommandName {
+	case utils.CommandNames.Listening, utils.CommandNames.Verify:
+		return "user:" + s.discordMessage.UserID
+	}
+	return "job:" + s.discordMessage.CommandName
+}
+
+// UpdateNickName renames the member, with reason shown in Discord's audit log.
+func (s *CommandHandler) UpdateNickName(userId string, newNickName string, reason string) error {
+	if utf8.RuneCountInString(newNickName) > utils.MAX_NICKNAME_LENGTH {
 		logrus.Error("Must be 32 or fewer in length.")
 		return errors.New("Must be 32 or fewer in length.")
 	}
-	session, err := CreateSession()
+	session, err := s.discordClient()
 	if err != nil {
 		return err
 	}
-	err = session.GuildMemberNickname(config.AppConfig.GUILD_ID, userId, newNickName)
+	entry := audit.Entry{
+		Actor:   s.auditActor(),
+		Target:  userId,
+		Action:  audit.ActionNicknameUpdate,
+		Reason:  reason,
+		Details: map[string]string{"nickname": newNickName},
+	}
+	err = audit.Apply(entry, func(auditReason discordgo.RequestOption) error {
+		return session.GuildMemberNickname(s.guildID(), userId, newNickName, auditReason)
+	})
 	if err != nil {
 		logrus.Errorf("Cannot update nickname: %v", err)
-		return nil
+		return err
+	}
+	return nil
+}
+
+// SendEphemeralFollowUp posts a follow-up message, visible only to the invoking user,
+// to the interaction that produced the data packet.
+func SendEphemeralFollowUp(dataPacket *dtos.DataPacket, content string) error {
+	applicationId := dataPacket.MetaData["applicationId"]
+	token := dataPacket.MetaData["token"]
+	if applicationId == "" || token == "" {
+		return errors.New("data packet does not reference an interaction")
+	}
+	session, err := CS.discordClient()
+	if err != nil {
+		return err
+	}
+	params := &discordgo.WebhookParams{
+		Content: content,
+		Flags:   discordgo.MessageFlagsEphemeral,
+	}
+	if _, err := session.WebhookExecute(applicationId, token, false, params); err != nil {
+		logrus.Errorf("Cannot send follow-up message: %v", err)
+		return err
 	}
 	return nil
 }
@@ -2,8 +2,13 @@ package handlers
 
 import (
 	"errors"
+	"net/http"
+	"net/url"
+	"strings"
 	"testing"
 
+	"github.com/Real-Dev-Squad/discord-service/audit"
+	"github.com/Real-Dev-Squad/discord-service/discord"
 	"github.com/Real-Dev-Squad/discord-service/dtos"
 	_ "github.com/Real-Dev-Squad/discord-service/tests/helpers"
 	"github.com/Real-Dev-Squad/discord-service/utils"
@@ -37,6 +42,11 @@ func TestMainHandler(t *testing.T) {
 		handler := MainHandler(data)
 		assert.NotNil(t, handler)
 	})
+	t.Run("should return memberSync for 'memberSync' command", func(t *testing.T) {
+		data, err := dtos.ToByte(&dtos.DataPacket{CommandName: utils.CommandNames.MemberSync})
+		assert.NoError(t, err)
+		assert.NotNil(t, MainHandler(data))
+	})
 	t.Run("should return nil for invalid json data", func(t *testing.T) {
 		invalidData := []byte(`{"userId":"1234567890","cmdName":"listening"}`)
 		handler := MainHandler(invalidData)
@@ -55,53 +65,177 @@ func TestMainHandler(t *testing.T) {
 	})
 }
 
-func TestCreateSession(t *testing.T) {
+func TestNewDiscordClient(t *testing.T) {
 	t.Run("should fail if NewDiscord returns an error", func(t *testing.T) {
 		originalNewDiscord := NewDiscord
 		defer func() { NewDiscord = originalNewDiscord }()
 		NewDiscord = func(token string) (s *discordgo.Session, err error) {
 			return nil, errors.New("testing error")
 		}
-		_, err := CreateSession()
+		_, err := NewDiscordClient()
 		assert.Error(t, err)
 	})
-	t.Run("should initiate open session if NewDiscord returns no error", func(t *testing.T) {
+	t.Run("should return a client without opening a gateway session", func(t *testing.T) {
 		originalNewDiscord := NewDiscord
 		defer func() { NewDiscord = originalNewDiscord }()
 		NewDiscord = func(token string) (s *discordgo.Session, err error) {
-			return &discordgo.Session{}, nil
+			return &discordgo.Session{Client: &http.Client{}, ShouldRetryOnRateLimit: true}, nil
 		}
-		assert.Panics(t, func() { CreateSession() })
+		client, err := NewDiscordClient()
+		assert.NoError(t, err)
+		session := client.(*discordgo.Session)
+		assert.IsType(t, &discord.RateLimitTransport{}, session.Client.Transport)
+		assert.False(t, session.ShouldRetryOnRateLimit)
 	})
 }
 
-func mockCreateSession() (*discordgo.Session, error) {
-	return &discordgo.Session{}, nil
+func TestSetDiscordClient(t *testing.T) {
+	originalClient := CS.discord
+	defer func() { CS.discord = originalClient }()
+
+	t.Run("should share the injected client with every handler", func(t *testing.T) {
+		client := &mockNicknameSession{}
+		SetDiscordClient(client)
+		session, err := CS.discordClient()
+		assert.NoError(t, err)
+		assert.Equal(t, client, session)
+	})
+	t.Run("should return error when no client was injected", func(t *testing.T) {
+		SetDiscordClient(nil)
+		_, err := CS.discordClient()
+		assert.ErrorIs(t, err, ErrDiscordClientNotInitialized)
+	})
+}
+
+type mockNicknameSession struct {
+	*discordgo.Session
+	nickname string
+	reason   string
+	err      error
+}
+
+func (m *mockNicknameSession) GuildMemberNickname(guildID, userID, nickname string, options ...discordgo.RequestOption) error {
+	m.nickname = nickname
+	m.reason = auditReason(options)
+	return m.err
+}
+
+// auditReason returns the decoded X-Audit-Log-Reason set by options.
+func auditReason(options []discordgo.RequestOption) string {
+	config := &discordgo.RequestConfig{Request: &http.Request{Header: http.Header{}}}
+	for _, option := range options {
+		option(config)
+	}
+	reason, _ := url.PathUnescape(config.Request.Header.Get("X-Audit-Log-Reason"))
+	return reason
 }
-func TestUpdateNickName(t *testing.T) {
 
-	var originalCreateSession = CreateSession
-	defer func() { CreateSession = originalCreateSession }()
+func captureAudit(t *testing.T) *[]audit.Entry {
+	originalWrite := audit.Write
+	t.Cleanup(func() { audit.Write = originalWrite })
+	entries := &[]audit.Entry{}
+	audit.Write = func(entry audit.Entry) {
+		*entries = append(*entries, entry)
+	}
+	return entries
+}
 
+func TestUpdateNickName(t *testing.T) {
 	t.Run("should return error if newNickName is longer than 32 characters", func(t *testing.T) {
-		err := UpdateNickName("userID", "ThisIsAVeryLongNicknameThatExceedsTheLimit")
+		handler := &CommandHandler{discord: &mockNicknameSession{}}
+		err := handler.UpdateNickName("userID", "ThisIsAVeryLongNicknameThatExceedsTheLimit", "reason")
 		assert.Error(t, err)
 		assert.Equal(t, "Must be 32 or fewer in length.", err.Error())
 	})
+	t.Run("should count the length in characters, not bytes", func(t *testing.T) {
+		session := &mockNicknameSession{}
+		handler := &CommandHandler{discord: session}
+		nickName := utils.FormatNickname(strings.Repeat("é", 19), utils.ListeningDecoration)
+		assert.NoError(t, handler.UpdateNickName("userID", nickName, "reason"))
+		assert.Equal(t, nickName, session.nickname)
+	})
+	t.Run("should return error if the discord client is not initialized", func(t *testing.T) {
+		handler := &CommandHandler{}
+		err := handler.UpdateNickName("userID", "validNickname", "reason")
+		assert.ErrorIs(t, err, ErrDiscordClientNotInitialized)
+	})
+	t.Run("should update the nickname through the injected client", func(t *testing.T) {
+		session := &mockNicknameSession{}
+		handler := &CommandHandler{discord: session}
+		err := handler.UpdateNickName("userID", "validNickname", "reason")
+		assert.NoError(t, err)
+		assert.Equal(t, "validNickname", session.nickname)
+	})
+	t.Run("should send the reason to the audit log and record the change", func(t *testing.T) {
+		entries := captureAudit(t)
+		session := &mockNicknameSession{}
+		handler := &CommandHandler{discord: session, discordMessage: &dtos.DataPacket{UserID: "userID", CommandName: utils.CommandNames.Listening}}
+		err := handler.UpdateNickName("userID", "validNickname", "listening mode enabled via /listening by joy")
+		assert.NoError(t, err)
+		assert.Equal(t, "listening mode enabled via /listening by joy", session.reason)
+		assert.Len(t, *entries, 1)
+		assert.Equal(t, audit.Entry{
+			Actor:   "user:userID",
+			Target:  "userID",
+			Action:  audit.ActionNicknameUpdate,
+			Reason:  "listening mode enabled via /listening by joy",
+			Outcome: audit.OutcomeSuccess,
+			Details: map[string]string{"nickname": "validNickname"},
+		}, (*entries)[0])
+	})
+	t.Run("should record a failed change and return its error so the job is retried", func(t *testing.T) {
+		entries := captureAudit(t)
+		handler := &CommandHandler{discord: &mockNicknameSession{err: errors.New("missing permissions")}}
+		assert.ErrorContains(t, handler.UpdateNickName("userID", "validNickname", "reason"), "missing permissions")
+		assert.Equal(t, audit.OutcomeFailure, (*entries)[0].Outcome)
+		assert.Equal(t, DiscordService, (*entries)[0].Actor)
+	})
+}
 
-	t.Run("should return error if CreateSession fails", func(t *testing.T) {
-		CreateSession = func() (DiscordSessionWrapper, error) {
-			return nil, errors.New("failed to create session")
-		}
-		err := UpdateNickName("userID", "validNickname")
+type mockFollowUpSession struct {
+	*discordgo.Session
+	params *discordgo.WebhookParams
+	err    error
+}
+
+func (m *mockFollowUpSession) WebhookExecute(webhookID, token string, wait bool, data *discordgo.WebhookParams, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	m.params = data
+	return nil, m.err
+}
+
+func TestSendEphemeralFollowUp(t *testing.T) {
+	originalClient := CS.discord
+	defer func() { CS.discord = originalClient }()
+	dataPacket := &dtos.DataPacket{
+		MetaData: map[string]string{
+			"applicationId": "applicationId",
+			"token":         "token",
+		},
+	}
+
+	t.Run("should return error if data packet does not reference an interaction", func(t *testing.T) {
+		err := SendEphemeralFollowUp(&dtos.DataPacket{}, "message")
 		assert.Error(t, err)
-		assert.Equal(t, "failed to create session", err.Error())
 	})
-	t.Run("should hit GuildMemberNickname if CreateSession succeeds", func(t *testing.T) {
-		CreateSession = func() (DiscordSessionWrapper, error) {
-			panic("GuildMemberNickname called")
-		}
-		assert.Panics(t, func() { UpdateNickName("userID", "validNickname") })
+
+	t.Run("should return error if the discord client is not initialized", func(t *testing.T) {
+		CS.discord = nil
+		err := SendEphemeralFollowUp(dataPacket, "message")
+		assert.Error(t, err)
 	})
 
+	t.Run("should return error if WebhookExecute fails", func(t *testing.T) {
+		CS.discord = &mockFollowUpSession{err: errors.New("webhook error")}
+		err := SendEphemeralFollowUp(dataPacket, "message")
+		assert.Error(t, err)
+	})
+
+	t.Run("should send an ephemeral follow-up message", func(t *testing.T) {
+		session := &mockFollowUpSession{}
+		CS.discord = session
+		err := SendEphemeralFollowUp(dataPacket, "message")
+		assert.NoError(t, err)
+		assert.Equal(t, "message", session.params.Content)
+		assert.Equal(t, discordgo.MessageFlagsEphemeral, session.params.Flags)
+	})
 }
@@ -0,0 +1,39 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/Real-Dev-Squad/discord-service/config"
+)
+
+// memberSync reports a guild member joining or leaving to the RDS backend.
+// Returning an error lets the queue retry the call.
+func (s *CommandHandler) memberSync() error {
+	metaData := s.discordMessage.MetaData
+	requestBody := map[string]any{
+		"discordId":     s.discordMessage.UserID,
+		"userName":      metaData["userName"],
+		"discordLeftAt": metaData["discordLeftAt"],
+	}
+	// Leave events carry no join time, sending an empty one would erase it.
+	if joinedAt, ok := metaData["discordJoinedAt"]; ok && joinedAt != "" {
+		requestBody["discordJoinedAt"] = joinedAt
+	}
+
+	url := fmt.Sprintf("%s%s", config.AppConfig.RDS_BASE_API_URL, MemberSyncPath)
+	request, err := newRDSRequest("POST", url, requestBody)
+	if err != nil {
+		return err
+	}
+
+	response, err := sendRDSRequest(request)
+	if err != nil {
+		return fmt.Errorf("error sending request to RDS Backend API: %v", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return fmt.Errorf("RDS Backend API responded with status %d while syncing member %s", response.StatusCode, s.discordMessage.UserID)
+	}
+	return nil
+}
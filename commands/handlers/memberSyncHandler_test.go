@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Real-Dev-Squad/discord-service/config"
+	"github.com/Real-Dev-Squad/discord-service/dtos"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemberSync(t *testing.T) {
+	pemPrivateKey := pemEncodePrivateKey(generateTestPrivateKey(t))
+	originalBotPrivateKey := config.AppConfig.BOT_PRIVATE_KEY
+	originalBaseURL := config.AppConfig.RDS_BASE_API_URL
+	defer func() {
+		config.AppConfig.BOT_PRIVATE_KEY = originalBotPrivateKey
+		config.AppConfig.RDS_BASE_API_URL = originalBaseURL
+	}()
+	config.AppConfig.BOT_PRIVATE_KEY = config.NewSecret(pemPrivateKey)
+
+	dataPacket := &dtos.DataPacket{
+		UserID: "1",
+		MetaData: map[string]string{
+			"userName":        "joy",
+			"discordJoinedAt": "2024-01-01T00:00:00Z",
+			"discordLeftAt":   "",
+		},
+	}
+
+	t.Run("should send the member to the RDS backend with a service token", func(t *testing.T) {
+		var body map[string]any
+		var authorization, path string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authorization = r.Header.Get(DefaultHeaders.Authorization)
+			path = r.URL.Path
+			json.NewDecoder(r.Body).Decode(&body)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+		config.AppConfig.RDS_BASE_API_URL = server.URL
+
+		handler := &CommandHandler{discordMessage: dataPacket}
+		err := handler.memberSync()
+		assert.NoError(t, err)
+		assert.Equal(t, MemberSyncPath, path)
+		assert.True(t, strings.HasPrefix(authorization, "Bearer "))
+		assert.Equal(t, "1", body["discordId"])
+		assert.Equal(t, "joy", body["userName"])
+		assert.Equal(t, "2024-01-01T00:00:00Z", body["discordJoinedAt"])
+		assert.Equal(t, "", body["discordLeftAt"])
+	})
+
+	t.Run("should leave out the join time of members who left", func(t *testing.T) {
+		var body map[string]any
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			json.NewDecoder(r.Body).Decode(&body)
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+		config.AppConfig.RDS_BASE_API_URL = server.URL
+
+		left := &dtos.DataPacket{UserID: "1", MetaData: map[string]string{"userName": "joy", "discordLeftAt": "2024-02-01T00:00:00Z"}}
+		handler := &CommandHandler{discordMessage: left}
+		assert.NoError(t, handler.memberSync())
+		assert.Equal(t, "2024-02-01T00:00:00Z", body["discordLeftAt"])
+		assert.NotContains(t, body, "discordJoinedAt")
+	})
+
+	t.Run("should return error so the job is retried when the backend rejects it", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+		config.AppConfig.RDS_BASE_API_URL = server.URL
+
+		handler := &CommandHandler{discordMessage: dataPacket}
+		err := handler.memberSync()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "503")
+	})
+
+	t.Run("should return error when the backend is unreachable", func(t *testing.T) {
+		config.AppConfig.RDS_BASE_API_URL = "http://localhost:12345"
+		handler := &CommandHandler{discordMessage: dataPacket}
+		err := handler.memberSync()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "error sending request to RDS Backend API")
+	})
+
+	t.Run("should return error when the private key is invalid", func(t *testing.T) {
+		config.AppConfig.BOT_PRIVATE_KEY = config.NewSecret("<invalid-key>")
+		defer func() { config.AppConfig.BOT_PRIVATE_KEY = config.NewSecret(pemPrivateKey) }()
+		handler := &CommandHandler{discordMessage: dataPacket}
+		err := handler.memberSync()
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "error parsing private key string to rsa private key")
+	})
+}
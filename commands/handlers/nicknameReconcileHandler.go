@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Real-Dev-Squad/discord-service/dtos"
+	"github.com/Real-Dev-Squad/discord-service/queue"
+	"github.com/Real-Dev-Squad/discord-service/utils"
+	"github.com/bwmarrin/discordgo"
+	"github.com/sirupsen/logrus"
+)
+
+var Publish = queue.SendMessage
+
+// NicknameRepair is a nickname whose listening decoration has to be fixed.
+type NicknameRepair struct {
+	UserID string
+	From   string
+	To     string
+}
+
+// NicknameReport summarises a nickname reconciliation run.
+type NicknameReport struct {
+	Scanned  int
+	Repairs  []NicknameRepair
+	Failures int
+}
+
+// repairNickname returns the nickname with a well-formed listening decoration.
+// The suffix marks a member as listening, so a nickname containing it gets
+// exactly one prefix and one suffix. Without the suffix, only a leftover prefix
+// is removed and the rest of the nickname is left to the member. Other
+// decorations are kept.
+func repairNickname(nickName string) string {
+	base, decorations := utils.ParseNickname(nickName)
+	listening := strings.Contains(base, utils.NICKNAME_SUFFIX)
+	for _, decoration := range decorations {
+		listening = listening || decoration.Name == utils.ListeningDecoration.Name
+	}
+	if !listening {
+		for strings.HasPrefix(base, utils.NICKNAME_PREFIX) {
+			base = strings.TrimPrefix(base, utils.NICKNAME_PREFIX)
+		}
+		return utils.FormatNickname(base, decorations...)
+	}
+	base = strings.ReplaceAll(base, utils.NICKNAME_SUFFIX, "")
+	base = strings.ReplaceAll(base, utils.NICKNAME_PREFIX, "")
+	return utils.FormatNickname(base, append(decorations, utils.ListeningDecoration)...)
+}
+
+// EnqueueNicknameReconcile publishes a nickname reconciliation job. It is run
+// on a schedule.
+func EnqueueNicknameReconcile() {
+	dataPacket := &dtos.DataPacket{CommandName: utils.CommandNames.NicknameReconcile}
+	bytePacket, err := dtos.ToByte(dataPacket)
+	if err != nil {
+		return
+	}
+	if err := Publish(bytePacket); err != nil {
+		logrus.Errorf("Failed to enqueue nickname reconciliation: %v", err)
+	}
+}
+
+// nicknameReconcile lists the guild members, enqueues a repair for every
+// malformed listening decoration and logs a report of the repairs.
+func (s *CommandHandler) nicknameReconcile() error {
+	session, err := s.discordClient()
+	if err != nil {
+		return fmt.Errorf("error getting discord client: %w", err)
+	}
+
+	report := &NicknameReport{}
+	err = forEachGuildMember(session, func(member *discordgo.Member) {
+		report.Scanned++
+		repaired := repairNickname(member.Nick)
+		if repaired == member.Nick {
+			return
+		}
+		repair := NicknameRepair{UserID: member.User.ID, From: member.Nick, To: repaired}
+		dataPacket := &dtos.DataPacket{
+			UserID:      member.User.ID,
+			CommandName: utils.CommandNames.NicknameRepair,
+			MetaData:    map[string]string{"nickname": member.Nick},
+		}
+		bytePacket, err := dtos.ToByte(dataPacket)
+		if err == nil {
+			err = Publish(bytePacket)
+		}
+		if err != nil {
+			logrus.Errorf("Failed to enqueue nickname repair of %s: %v", member.User.ID, err)
+			report.Failures++
+			return
+		}
+		report.Repairs = append(report.Repairs, repair)
+	})
+	if err != nil {
+		return err
+	}
+
+	report.log()
+	return nil
+}
+
+func (r *NicknameReport) log() {
+	for _, repair := range r.Repairs {
+		logrus.WithFields(logrus.Fields{"userId": repair.UserID, "from": repair.From, "to": repair.To}).Info("Nickname repair enqueued")
+	}
+	logrus.WithFields(logrus.Fields{
+		"scanned":  r.Scanned,
+		"repaired": len(r.Repairs),
+		"failed":   r.Failures,
+	}).Info("Finished nickname reconciliation")
+}
+
+// nicknameRepair fixes the decoration of one member. The repair is worked out
+// again from the live nickname and dropped if the member renamed themselves
+// since the reconciliation found it.
+func (s *CommandHandler) nicknameRepair() error {
+	session, err := s.discordClient()
+	if err != nil {
+		return fmt.Errorf("error getting discord client: %w", err)
+	}
+	userID := s.discordMessage.UserID
+	member, err := session.GuildMember(s.guildID(), userID)
+	if err != nil {
+		return fmt.Errorf("error fetching guild member %s: %v", userID, err)
+	}
+	if member.Nick != s.discordMessage.MetaData["nickname"] {
+		logrus.Infof("Skipping nickname repair of %s, the nickname changed since it was found", userID)
+		return nil
+	}
+	repaired := repairNickname(member.Nick)
+	if repaired == member.Nick {
+		return nil
+	}
+	logrus.WithFields(logrus.Fields{"userId": userID, "from": member.Nick, "to": repaired}).Info("Repairing nickname")
+	return s.UpdateNickName(userID, repaired, "listening nickname decoration repaired by scheduled reconciliation")
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Real-Dev-Squad/discord-service/dtos"
+	"github.com/Real-Dev-Squad/discord-service/utils"
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	prefix = utils.NICKNAME_PREFIX
+	suffix = utils.NICKNAME_SUFFIX
+)
+
+type mockMembersSession struct {
+	*discordgo.Session
+	members  []*discordgo.Member
+	nickname *string
+	reason   string
+}
+
+func (m *mockMembersSession) GuildMembers(guildID, after string, limit int, options ...discordgo.RequestOption) ([]*discordgo.Member, error) {
+	if after == "" {
+		return m.members, nil
+	}
+	return nil, nil
+}
+
+func (m *mockMembersSession) GuildMember(guildID, userID string, options ...discordgo.RequestOption) (*discordgo.Member, error) {
+	for _, member := range m.members {
+		if member.User.ID == userID {
+			return member, nil
+		}
+	}
+	return nil, errors.New("unknown member")
+}
+
+func (m *mockMembersSession) GuildMemberNickname(guildID, userID, nickname string, options ...discordgo.RequestOption) error {
+	m.nickname = &nickname
+	m.reason = auditReason(options)
+	return nil
+}
+
+func capturePublished(t *testing.T) *[]*dtos.DataPacket {
+	originalPublish := Publish
+	t.Cleanup(func() { Publish = originalPublish })
+	published := &[]*dtos.DataPacket{}
+	Publish = func(message []byte) error {
+		dataPacket := &dtos.DataPacket{}
+		assert.NoError(t, dataPacket.FromByte(message))
+		*published = append(*published, dataPacket)
+		return nil
+	}
+	return published
+}
+
+func TestRepairNickname(t *testing.T) {
+	tests := []struct {
+		name     string
+		nickname string
+		want     string
+	}{
+		{"should keep a well-formed listening nickname", prefix + "joy" + suffix, prefix + "joy" + suffix},
+		{"should keep a nickname without decoration", "joy", "joy"},
+		{"should keep an empty nickname", "", ""},
+		{"should add the prefix to a suffix without prefix", "joy" + suffix, prefix + "joy" + suffix},
+		{"should collapse duplicated suffixes", prefix + "joy" + suffix + suffix, prefix + "joy" + suffix},
+		{"should collapse duplicated prefixes", prefix + prefix + "joy" + suffix, prefix + "joy" + suffix},
+		{"should move a suffix the member typed after back to the end", prefix + "joy" + suffix + " away", prefix + "joy away" + suffix},
+		{"should remove a leftover prefix without suffix", prefix + prefix + "joy", "joy"},
+		{"should shorten a repaired nickname that is too long", "ThisNicknameIsFarTooLong" + suffix + suffix, prefix + "ThisNicknameIsFarTo" + suffix},
+		{"should keep other decorations", utils.OnCallDecoration.Prefix + "joy" + suffix, utils.OnCallDecoration.Prefix + prefix + "joy" + suffix},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, repairNickname(test.nickname))
+		})
+	}
+}
+
+func TestNicknameReconcile(t *testing.T) {
+	members := []*discordgo.Member{
+		{User: &discordgo.User{ID: "1"}, Nick: prefix + "joy" + suffix},
+		{User: &discordgo.User{ID: "2"}, Nick: "ankush" + suffix},
+		{User: &discordgo.User{ID: "3"}, Nick: prefix + "ThisNicknameIsFarTooLong" + suffix + suffix},
+		{User: &discordgo.User{ID: "4", Bot: true}, Nick: "bot" + suffix},
+	}
+
+	t.Run("should enqueue a repair for every malformed nickname", func(t *testing.T) {
+		published := capturePublished(t)
+		handler := &CommandHandler{discord: &mockMembersSession{members: members}, discordMessage: &dtos.DataPacket{}}
+		assert.NoError(t, handler.nicknameReconcile())
+		assert.Len(t, *published, 2)
+		assert.Equal(t, "2", (*published)[0].UserID)
+		assert.Equal(t, utils.CommandNames.NicknameRepair, (*published)[0].CommandName)
+		assert.Equal(t, "ankush"+suffix, (*published)[0].MetaData["nickname"])
+		assert.Equal(t, "3", (*published)[1].UserID)
+	})
+
+	t.Run("should enqueue the reconciliation job", func(t *testing.T) {
+		published := capturePublished(t)
+		EnqueueNicknameReconcile()
+		assert.Len(t, *published, 1)
+		assert.Equal(t, utils.CommandNames.NicknameReconcile, (*published)[0].CommandName)
+	})
+
+	t.Run("should return error if the discord client is not initialized", func(t *testing.T) {
+		handler := &CommandHandler{discordMessage: &dtos.DataPacket{}}
+		assert.ErrorIs(t, handler.nicknameReconcile(), ErrDiscordClientNotInitialized)
+	})
+}
+
+func TestNicknameRepair(t *testing.T) {
+	t.Run("should repair the live nickname", func(t *testing.T) {
+		session := &mockMembersSession{members: []*discordgo.Member{{User: &discordgo.User{ID: "2"}, Nick: "ankush" + suffix}}}
+		handler := &CommandHandler{discord: session, discordMessage: &dtos.DataPacket{UserID: "2", MetaData: map[string]string{"nickname": "ankush" + suffix}}}
+		assert.NoError(t, handler.nicknameRepair())
+		assert.Equal(t, prefix+"ankush"+suffix, *session.nickname)
+	})
+
+	t.Run("should skip the repair when the member renamed themselves", func(t *testing.T) {
+		session := &mockMembersSession{members: []*discordgo.Member{{User: &discordgo.User{ID: "2"}, Nick: "ankush"}}}
+		handler := &CommandHandler{discord: session, discordMessage: &dtos.DataPacket{UserID: "2", MetaData: map[string]string{"nickname": "ankush" + suffix}}}
+		assert.NoError(t, handler.nicknameRepair())
+		assert.Nil(t, session.nickname)
+	})
+
+	t.Run("should return error when the member cannot be fetched", func(t *testing.T) {
+		handler := &CommandHandler{discord: &mockMembersSession{}, discordMessage: &dtos.DataPacket{UserID: "2", MetaData: map[string]string{}}}
+		assert.Error(t, handler.nicknameRepair())
+	})
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Real-Dev-Squad/discord-service/config"
+	"github.com/Real-Dev-Squad/discord-service/utils"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// generateServiceToken signs a short-lived RS256 token that identifies this
+// service to the RDS backend.
+func generateServiceToken() (string, error) {
+	rsaPrivateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(config.AppConfig.BOT_PRIVATE_KEY.Value()))
+	if err != nil {
+		return "", fmt.Errorf("error parsing private key string to rsa private key: %v", err)
+	}
+
+	authToken := &utils.AuthToken{}
+	authTokenString, err := authToken.GenerateAuthToken(jwt.SigningMethodRS256, jwt.MapClaims{
+		"expiry": time.Now().Add(time.Second * 15).Unix(),
+		"name":   DiscordService,
+	}, rsaPrivateKey)
+	if err != nil {
+		return "", fmt.Errorf("error generating auth token: %v", err)
+	}
+	return authTokenString, nil
+}
+
+// newRDSRequest builds a request to the RDS backend signed with a service token.
+// A nil body sends no body.
+func newRDSRequest(method string, url string, body any) (*http.Request, error) {
+	authTokenString, err := generateServiceToken()
+	if err != nil {
+		return nil, err
+	}
+
+	var requestBody io.Reader
+	if body != nil {
+		jsonBytes, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing request body in json bytes: %v", err)
+		}
+		requestBody = bytes.NewBuffer(jsonBytes)
+	}
+
+	request, err := http.NewRequest(method, url, requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("error creating http request: %v", err)
+	}
+
+	request.Header.Set(DefaultHeaders.Authorization, fmt.Sprintf("Bearer %s", authTokenString))
+	request.Header.Set(DefaultHeaders.ContentType, "application/json")
+	request.Header.Set(DefaultHeaders.Service, DiscordService)
+	return request, nil
+}
+
+// sendRDSRequest sends a request built by newRDSRequest, giving up after
+// RDS_REQUEST_TIMEOUT.
+func sendRDSRequest(request *http.Request) (*http.Response, error) {
+	client := &http.Client{Timeout: config.AppConfig.RDS_REQUEST_TIMEOUT}
+	return client.Do(request)
+}
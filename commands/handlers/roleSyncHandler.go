@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/Real-Dev-Squad/discord-service/audit"
+	"github.com/Real-Dev-Squad/discord-service/config"
+	"github.com/Real-Dev-Squad/discord-service/utils"
+	"github.com/bwmarrin/discordgo"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	RoleAdd    = "add"
+	RoleRemove = "remove"
+)
+
+// RoleChange is a guild role that has to be added to or removed from a member
+// for their Discord roles to match their RDS roles.
+type RoleChange struct {
+	Action   string
+	RoleName string
+	RoleID   string
+}
+
+var errRDSUserNotFound = errors.New("user is not linked to an RDS account")
+
+type rdsUserResponse struct {
+	User struct {
+		Roles map[string]bool `json:"roles"`
+	} `json:"user"`
+}
+
+// computeRoleChanges compares the RDS roles of a member with the guild roles
+// they hold and returns the changes needed to reconcile them. Only roles
+// present in mappings are managed, in the order of their RDS role names.
+func computeRoleChanges(rdsRoles map[string]bool, memberRoles []string, mappings map[string]string) []RoleChange {
+	held := make(map[string]bool, len(memberRoles))
+	for _, roleID := range memberRoles {
+		held[roleID] = true
+	}
+
+	names := make([]string, 0, len(mappings))
+	for name := range mappings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	changes := []RoleChange{}
+	for _, name := range names {
+		roleID := mappings[name]
+		if roleID == "" {
+			continue
+		}
+		if rdsRoles[name] && !held[roleID] {
+			changes = append(changes, RoleChange{Action: RoleAdd, RoleName: name, RoleID: roleID})
+		} else if !rdsRoles[name] && held[roleID] {
+			changes = append(changes, RoleChange{Action: RoleRemove, RoleName: name, RoleID: roleID})
+		}
+	}
+	return changes
+}
+
+// roleSync reconciles the guild roles of the user in the data packet with their
+// RDS roles, or of every member when no user is given. With the "dryRun"
+// metadata set to "true" changes are only logged.
+func (s *CommandHandler) roleSync() error {
+	dryRun := s.discordMessage.MetaData["dryRun"] == "true"
+	session, err := s.discordClient()
+	if err != nil {
+		return fmt.Errorf("error getting discord client: %w", err)
+	}
+
+	if s.discordMessage.UserID == "" {
+		return syncGuildRoles(session, dryRun)
+	}
+
+	member, err := session.GuildMember(config.AppConfig.GUILD_ID, s.discordMessage.UserID)
+	if err != nil {
+		return fmt.Errorf("error fetching guild member %s: %v", s.discordMessage.UserID, err)
+	}
+	_, err = syncMemberRoles(session, member, dryRun)
+	return err
+}
+
+// syncGuildRoles reconciles the roles of every member of the guild. A member
+// that fails is logged and skipped so that one user cannot hold back the sweep.
+func syncGuildRoles(session DiscordSessionWrapper, dryRun bool) error {
+	synced, changed, failed := 0, 0, 0
+	err := forEachGuildMember(session, func(member *discordgo.Member) {
+		changes, err := syncMemberRoles(session, member, dryRun)
+		if err != nil {
+			logrus.Errorf("Failed to sync roles of %s: %v", member.User.ID, err)
+			failed++
+			return
+		}
+		synced++
+		changed += len(changes)
+	})
+	if err != nil {
+		return err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"synced":  synced,
+		"changes": changed,
+		"failed":  failed,
+		"dryRun":  dryRun,
+	}).Info("Finished guild role sync")
+	return nil
+}
+
+// syncMemberRoles applies the role changes a member needs and writes an audit
+// record for each of them.
+func syncMemberRoles(session DiscordSessionWrapper, member *discordgo.Member, dryRun bool) ([]RoleChange, error) {
+	userID := member.User.ID
+	rdsRoles, err := fetchRDSRoles(userID)
+	if errors.Is(err, errRDSUserNotFound) {
+		logrus.Debugf("Skipping role sync of %s: %v", userID, err)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	changes := computeRoleChanges(rdsRoles, member.Roles, config.AppConfig.ROLE_MAPPINGS)
+	var errs []error
+	for _, change := range changes {
+		entry := audit.Entry{
+			Actor:   "job:" + utils.CommandNames.RoleSync,
+			Target:  userID,
+			Action:  audit.ActionRoleAdd,
+			Reason:  fmt.Sprintf("role sync: %s role granted in RDS", change.RoleName),
+			Details: map[string]string{"roleName": change.RoleName, "roleId": change.RoleID},
+		}
+		if change.Action == RoleRemove {
+			entry.Action = audit.ActionRoleRemove
+			entry.Reason = fmt.Sprintf("role sync: %s role revoked in RDS", change.RoleName)
+		}
+		if dryRun {
+			audit.DryRun(entry)
+			continue
+		}
+
+		err := audit.Apply(entry, func(reason discordgo.RequestOption) error {
+			if change.Action == RoleAdd {
+				return session.GuildMemberRoleAdd(config.AppConfig.GUILD_ID, userID, change.RoleID, reason)
+			}
+			return session.GuildMemberRoleRemove(config.AppConfig.GUILD_ID, userID, change.RoleID, reason)
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error applying %s of role %s: %v", change.Action, change.RoleName, err))
+		}
+	}
+	return changes, errors.Join(errs...)
+}
+
+// fetchRDSRoles reads the roles of the RDS user linked to discordId.
+func fetchRDSRoles(discordId string) (map[string]bool, error) {
+	url := config.AppConfig.RDS_BASE_API_URL + fmt.Sprintf(UserByDiscordIdPath, discordId)
+	request, err := newRDSRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := sendRDSRequest(request)
+	if err != nil {
+		return nil, fmt.Errorf("error sending request to RDS Backend API: %v", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil, errRDSUserNotFound
+	}
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		return nil, fmt.Errorf("RDS Backend API responded with status %d while fetching user %s", response.StatusCode, discordId)
+	}
+
+	user := &rdsUserResponse{}
+	if err := json.NewDecoder(response.Body).Decode(user); err != nil {
+		return nil, fmt.Errorf("error decoding RDS user: %v", err)
+	}
+	return user.User.Roles, nil
+}
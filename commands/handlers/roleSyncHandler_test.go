@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Real-Dev-Squad/discord-service/audit"
+	"github.com/Real-Dev-Squad/discord-service/config"
+	"github.com/Real-Dev-Squad/discord-service/dtos"
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockRoleSession struct {
+	*discordgo.Session
+	members []*discordgo.Member
+	added   []string
+	removed []string
+	roleErr error
+	afters  []string
+	reasons []string
+}
+
+func (m *mockRoleSession) GuildMember(guildID, userID string, options ...discordgo.RequestOption) (*discordgo.Member, error) {
+	for _, member := range m.members {
+		if member.User.ID == userID {
+			return member, nil
+		}
+	}
+	return nil, errors.New("unknown member")
+}
+
+func (m *mockRoleSession) GuildMembers(guildID, after string, limit int, options ...discordgo.RequestOption) ([]*discordgo.Member, error) {
+	m.afters = append(m.afters, after)
+	if after == "" {
+		return m.members, nil
+	}
+	return nil, nil
+}
+
+func (m *mockRoleSession) GuildMemberRoleAdd(guildID, userID, roleID string, options ...discordgo.RequestOption) error {
+	m.added = append(m.added, userID+":"+roleID)
+	m.reasons = append(m.reasons, auditReason(options))
+	return m.roleErr
+}
+
+func (m *mockRoleSession) GuildMemberRoleRemove(guildID, userID, roleID string, options ...discordgo.RequestOption) error {
+	m.removed = append(m.removed, userID+":"+roleID)
+	m.reasons = append(m.reasons, auditReason(options))
+	return m.roleErr
+}
+
+func TestComputeRoleChanges(t *testing.T) {
+	mappings := map[string]string{"member": "10", "super_user": "20", "archived": "30"}
+
+	t.Run("should add mapped roles the user has in RDS but not on Discord", func(t *testing.T) {
+		changes := computeRoleChanges(map[string]bool{"member": true}, []string{}, mappings)
+		assert.Equal(t, []RoleChange{{Action: RoleAdd, RoleName: "member", RoleID: "10"}}, changes)
+	})
+
+	t.Run("should remove mapped roles the user no longer has in RDS", func(t *testing.T) {
+		changes := computeRoleChanges(map[string]bool{"member": true, "archived": false}, []string{"10", "30"}, mappings)
+		assert.Equal(t, []RoleChange{{Action: RoleRemove, RoleName: "archived", RoleID: "30"}}, changes)
+	})
+
+	t.Run("should leave unmapped guild roles alone", func(t *testing.T) {
+		changes := computeRoleChanges(map[string]bool{}, []string{"99"}, mappings)
+		assert.Empty(t, changes)
+	})
+
+	t.Run("should return no changes when roles are already in sync", func(t *testing.T) {
+		changes := computeRoleChanges(map[string]bool{"member": true, "super_user": true}, []string{"10", "20"}, mappings)
+		assert.Empty(t, changes)
+	})
+}
+
+func TestRoleSync(t *testing.T) {
+	pemPrivateKey := pemEncodePrivateKey(generateTestPrivateKey(t))
+	originalBotPrivateKey := config.AppConfig.BOT_PRIVATE_KEY
+	originalBaseURL := config.AppConfig.RDS_BASE_API_URL
+	originalMappings := config.AppConfig.ROLE_MAPPINGS
+	defer func() {
+		config.AppConfig.BOT_PRIVATE_KEY = originalBotPrivateKey
+		config.AppConfig.RDS_BASE_API_URL = originalBaseURL
+		config.AppConfig.ROLE_MAPPINGS = originalMappings
+	}()
+	config.AppConfig.BOT_PRIVATE_KEY = config.NewSecret(pemPrivateKey)
+	config.AppConfig.ROLE_MAPPINGS = map[string]string{"member": "10", "archived": "30"}
+
+	// Users 1 and 2 are members in RDS, user 3 is not linked to an RDS account.
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID := strings.TrimPrefix(r.URL.Path, "/users/discord/")
+		if userID == "3" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"user": {"roles": {"member": true, "archived": false}}}`)
+	}))
+	defer server.Close()
+	config.AppConfig.RDS_BASE_API_URL = server.URL
+
+	newSession := func() *mockRoleSession {
+		return &mockRoleSession{members: []*discordgo.Member{
+			{User: &discordgo.User{ID: "1"}, Roles: []string{"30"}},
+			{User: &discordgo.User{ID: "2"}, Roles: []string{"10"}},
+			{User: &discordgo.User{ID: "3"}, Roles: []string{"30"}},
+			{User: &discordgo.User{ID: "4", Bot: true}},
+		}}
+	}
+
+	t.Run("should reconcile the roles of a single user", func(t *testing.T) {
+		entries := captureAudit(t)
+		session := newSession()
+		handler := &CommandHandler{discord: session, discordMessage: &dtos.DataPacket{UserID: "1", MetaData: map[string]string{}}}
+		err := handler.roleSync()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"1:10"}, session.added)
+		assert.Equal(t, []string{"1:30"}, session.removed)
+		assert.Equal(t, []string{"role sync: archived role revoked in RDS", "role sync: member role granted in RDS"}, session.reasons)
+		assert.Len(t, *entries, 2)
+		assert.Equal(t, audit.ActionRoleRemove, (*entries)[0].Action)
+		assert.Equal(t, audit.OutcomeSuccess, (*entries)[0].Outcome)
+		assert.Equal(t, "job:roleSync", (*entries)[0].Actor)
+	})
+
+	t.Run("should not change roles in dry run", func(t *testing.T) {
+		entries := captureAudit(t)
+		session := newSession()
+		handler := &CommandHandler{discord: session, discordMessage: &dtos.DataPacket{UserID: "1", MetaData: map[string]string{"dryRun": "true"}}}
+		err := handler.roleSync()
+		assert.NoError(t, err)
+		assert.Empty(t, session.added)
+		assert.Empty(t, session.removed)
+		assert.Len(t, *entries, 2)
+		assert.Equal(t, audit.OutcomeDryRun, (*entries)[0].Outcome)
+	})
+
+	t.Run("should sweep every linked member of the guild", func(t *testing.T) {
+		session := newSession()
+		handler := &CommandHandler{discord: session, discordMessage: &dtos.DataPacket{MetaData: map[string]string{}}}
+		err := handler.roleSync()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{""}, session.afters)
+		assert.Equal(t, []string{"1:10"}, session.added)
+		assert.Equal(t, []string{"1:30"}, session.removed)
+	})
+
+	t.Run("should return error so the job is retried when a role change fails", func(t *testing.T) {
+		session := newSession()
+		session.roleErr = errors.New("discord error")
+		handler := &CommandHandler{discord: session, discordMessage: &dtos.DataPacket{UserID: "1", MetaData: map[string]string{}}}
+		err := handler.roleSync()
+		assert.Error(t, err)
+		assert.Len(t, session.added, 1)
+		assert.Len(t, session.removed, 1)
+	})
+
+	t.Run("should return error when the member cannot be fetched", func(t *testing.T) {
+		handler := &CommandHandler{discord: newSession(), discordMessage: &dtos.DataPacket{UserID: "5", MetaData: map[string]string{}}}
+		assert.Error(t, handler.roleSync())
+	})
+
+	t.Run("should return error if the discord client is not initialized", func(t *testing.T) {
+		handler := &CommandHandler{discordMessage: &dtos.DataPacket{UserID: "1"}}
+		assert.ErrorIs(t, handler.roleSync(), ErrDiscordClientNotInitialized)
+	})
+}
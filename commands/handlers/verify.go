@@ -1,22 +1,22 @@
 package handlers
 
 import (
-	"bytes"
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"time"
 
+	"github.com/Real-Dev-Squad/discord-service/audit"
 	"github.com/Real-Dev-Squad/discord-service/config"
+	"github.com/Real-Dev-Squad/discord-service/i18n"
 	"github.com/Real-Dev-Squad/discord-service/utils"
 	"github.com/bwmarrin/discordgo"
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/sirupsen/logrus"
 )
 
 func (CS *CommandHandler) verify() error {
 	metaData := CS.discordMessage.MetaData
 	applicationId := metaData["applicationId"]
+	locale := discordgo.Locale(metaData["locale"])
 
 	uniqueToken := &utils.UniqueToken{}
 	token, err := uniqueToken.GenerateUniqueToken()
@@ -24,20 +24,6 @@ func (CS *CommandHandler) verify() error {
 		return fmt.Errorf("error generating unique token: %v", err)
 	}
 
-	rsaPrivateKey, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(config.AppConfig.BOT_PRIVATE_KEY))
-	if err != nil {
-		return fmt.Errorf("error parsing private key string to rsa private key: %v", err)
-	}
-
-	authToken := &utils.AuthToken{}
-	authTokenString, err := authToken.GenerateAuthToken(jwt.SigningMethodRS256, jwt.MapClaims{
-		"expiry": time.Now().Add(time.Second * 15).Unix(),
-		"name":   DiscordService,
-	}, rsaPrivateKey)
-	if err != nil {
-		return fmt.Errorf("error generating auth token: %v", err)
-	}
-
 	baseUrl := fmt.Sprintf("%s/external-accounts", config.AppConfig.RDS_BASE_API_URL)
 	requestBody := map[string]any{
 		"type":  "discord",
@@ -52,31 +38,22 @@ func (CS *CommandHandler) verify() error {
 		},
 	}
 
-	jsonBytes, err := json.Marshal(requestBody)
-	if err != nil {
-		return fmt.Errorf("error parsing request body in json bytes: %v", err)
-	}
-
-	request, err := http.NewRequest("POST", baseUrl, bytes.NewBuffer(jsonBytes))
+	request, err := newRDSRequest("POST", baseUrl, requestBody)
 	if err != nil {
-		return fmt.Errorf("error creating http request: %v", err)
+		return err
 	}
 
-	request.Header.Set(DefaultHeaders.Authorization, fmt.Sprintf("Bearer %s", authTokenString))
-	request.Header.Set(DefaultHeaders.ContentType, "application/json")
-	request.Header.Set(DefaultHeaders.Service, DiscordService)
-
-	response, err := http.DefaultClient.Do(request)
+	response, err := sendRDSRequest(request)
 	if err != nil {
 		return fmt.Errorf("error sending request to RDS Backend API: %v", err)
 	}
 	defer response.Body.Close()
 
-	message := "Something went wrong while generating verification link"
+	message := i18n.T(locale, i18n.VerifyLinkFailed)
 	verificationSiteURL := ""
 
 	createMessage := func(format string) string {
-		return fmt.Sprintf(format, VerificationString, verificationSiteURL, token, VerificationNote)
+		return fmt.Sprintf(format, i18n.T(locale, i18n.VerifyLink), verificationSiteURL, token, i18n.T(locale, i18n.VerifyNote))
 	}
 
 	if response.StatusCode == http.StatusCreated || response.StatusCode == http.StatusOK {
@@ -91,24 +68,29 @@ func (CS *CommandHandler) verify() error {
 		}
 	}
 
-	session, err := CreateSession()
+	session, err := CS.discordClient()
 	if err != nil {
-		return fmt.Errorf("error creating session: %v", err)
+		return fmt.Errorf("error getting discord client: %v", err)
 	}
 
 	webhookEdit := &discordgo.WebhookEdit{
 		Content: &message,
 	}
 
-	if _, err := session.WebhookMessageEdit(applicationId, metaData["token"], "@original", webhookEdit); err != nil {
+	entry := audit.Entry{
+		Actor:  CS.auditActor(),
+		Target: CS.discordMessage.UserID,
+		Action: audit.ActionMessageEdit,
+		Reason: fmt.Sprintf("verification link requested via /verify by %s", metaData["userName"]),
+	}
+	err = audit.Apply(entry, func(reason discordgo.RequestOption) error {
+		_, err := session.WebhookMessageEdit(applicationId, metaData["token"], "@original", webhookEdit, reason)
+		return err
+	})
+	if err != nil {
 		logrus.Errorf("Error editing original message for application %v", err)
 		return fmt.Errorf("error editing original message for application %v", err)
 	}
 
-	if err := session.Close(); err != nil {
-		logrus.Errorf("Error closing session: %v", err)
-		return fmt.Errorf("error closing session: %v", err)
-	}
-
 	return nil
 }
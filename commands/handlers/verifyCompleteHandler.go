@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/Real-Dev-Squad/discord-service/audit"
+	"github.com/Real-Dev-Squad/discord-service/config"
+	"github.com/Real-Dev-Squad/discord-service/i18n"
+	"github.com/Real-Dev-Squad/discord-service/utils"
+	"github.com/bwmarrin/discordgo"
+	"github.com/sirupsen/logrus"
+)
+
+// verifyComplete runs once the RDS backend reports that the user linked their
+// account. It grants VERIFIED_ROLE_ID, optionally sets the nickname to the RDS
+// username and tells the user, by editing the /verify reply when the interaction
+// is referenced and by direct message otherwise.
+func (s *CommandHandler) verifyComplete() error {
+	session, err := s.discordClient()
+	if err != nil {
+		return fmt.Errorf("error getting discord client: %w", err)
+	}
+	userID := s.discordMessage.UserID
+	metaData := s.discordMessage.MetaData
+
+	if roleID := config.AppConfig.VERIFIED_ROLE_ID; roleID != "" {
+		entry := audit.Entry{
+			Actor:   s.auditActor(),
+			Target:  userID,
+			Action:  audit.ActionRoleAdd,
+			Reason:  "account linked to Real Dev Squad via /verify",
+			Details: map[string]string{"roleId": roleID},
+		}
+		err := audit.Apply(entry, func(reason discordgo.RequestOption) error {
+			return session.GuildMemberRoleAdd(s.guildID(), userID, roleID, reason)
+		})
+		if err != nil {
+			return fmt.Errorf("error granting verified role to %s: %v", userID, err)
+		}
+	}
+
+	if config.AppConfig.SYNC_VERIFIED_NICK && metaData["userName"] != "" {
+		if err := s.syncVerifiedNickname(session, userID, metaData["userName"]); err != nil {
+			// The account is linked either way, a nickname that cannot be set
+			// should not hold back the confirmation.
+			logrus.Errorf("Cannot sync nickname of verified user %s: %v", userID, err)
+		}
+	}
+
+	if err := s.notifyVerified(session, userID, metaData["applicationId"], metaData["token"]); err != nil {
+		// Retrying would grant the role and sync the nickname again for a
+		// confirmation the user may never be able to receive, e.g. with DMs closed.
+		logrus.Errorf("Cannot confirm verification to %s: %v", userID, err)
+	}
+	return nil
+}
+
+// syncVerifiedNickname sets the nickname to userName, keeping the status
+// decorations the member currently has.
+func (s *CommandHandler) syncVerifiedNickname(session DiscordSessionWrapper, userID string, userName string) error {
+	member, err := session.GuildMember(s.guildID(), userID)
+	if err != nil {
+		return err
+	}
+	_, decorations := utils.ParseNickname(member.Nick)
+	nickName := utils.FormatNickname(userName, decorations...)
+	return s.UpdateNickName(userID, nickName, "nickname synced to Real Dev Squad username after verification")
+}
+
+func (s *CommandHandler) notifyVerified(session DiscordSessionWrapper, userID string, applicationId string, token string) error {
+	message := i18n.T(discordgo.Locale(s.discordMessage.MetaData["locale"]), i18n.VerifyCompleted)
+	if applicationId != "" && token != "" {
+		entry := audit.Entry{
+			Actor:  s.auditActor(),
+			Target: userID,
+			Action: audit.ActionMessageEdit,
+			Reason: "verification confirmed",
+		}
+		err := audit.Apply(entry, func(reason discordgo.RequestOption) error {
+			_, err := session.WebhookMessageEdit(applicationId, token, "@original", &discordgo.WebhookEdit{Content: &message}, reason)
+			return err
+		})
+		if err == nil {
+			return nil
+		}
+		// Interaction tokens expire after 15 minutes, fall back to a direct message.
+		logrus.Warnf("Cannot edit verification message of %s, sending a direct message instead: %v", userID, err)
+	}
+
+	channel, err := session.UserChannelCreate(userID)
+	if err != nil {
+		return fmt.Errorf("error opening direct message channel with %s: %v", userID, err)
+	}
+	if _, err := session.ChannelMessageSend(channel.ID, message); err != nil {
+		return fmt.Errorf("error sending verification confirmation to %s: %v", userID, err)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Real-Dev-Squad/discord-service/audit"
+	"github.com/Real-Dev-Squad/discord-service/config"
+	"github.com/Real-Dev-Squad/discord-service/dtos"
+	"github.com/Real-Dev-Squad/discord-service/i18n"
+	"github.com/Real-Dev-Squad/discord-service/utils"
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockVerifiedSession struct {
+	*discordgo.Session
+	member    *discordgo.Member
+	roles     []string
+	guilds    []string
+	nickname  string
+	edited    string
+	editErr   error
+	dmChannel string
+	dmContent string
+	dmErr     error
+	roleErr   error
+}
+
+func (m *mockVerifiedSession) GuildMember(guildID, userID string, options ...discordgo.RequestOption) (*discordgo.Member, error) {
+	return m.member, nil
+}
+
+func (m *mockVerifiedSession) GuildMemberRoleAdd(guildID, userID, roleID string, options ...discordgo.RequestOption) error {
+	m.roles = append(m.roles, roleID)
+	m.guilds = append(m.guilds, guildID)
+	return m.roleErr
+}
+
+func (m *mockVerifiedSession) GuildMemberNickname(guildID, userID, nickname string, options ...discordgo.RequestOption) error {
+	m.nickname = nickname
+	return nil
+}
+
+func (m *mockVerifiedSession) WebhookMessageEdit(webhookID, token, messageID string, data *discordgo.WebhookEdit, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	m.edited = *data.Content
+	return nil, m.editErr
+}
+
+func (m *mockVerifiedSession) UserChannelCreate(recipientID string, options ...discordgo.RequestOption) (*discordgo.Channel, error) {
+	m.dmChannel = "dm-" + recipientID
+	return &discordgo.Channel{ID: m.dmChannel}, nil
+}
+
+func (m *mockVerifiedSession) ChannelMessageSend(channelID, content string, options ...discordgo.RequestOption) (*discordgo.Message, error) {
+	m.dmContent = content
+	return nil, m.dmErr
+}
+
+func TestVerifyComplete(t *testing.T) {
+	originalRoleID := config.AppConfig.VERIFIED_ROLE_ID
+	originalSyncNick := config.AppConfig.SYNC_VERIFIED_NICK
+	defer func() {
+		config.AppConfig.VERIFIED_ROLE_ID = originalRoleID
+		config.AppConfig.SYNC_VERIFIED_NICK = originalSyncNick
+	}()
+	config.AppConfig.VERIFIED_ROLE_ID = "40"
+
+	newPacket := func(metaData map[string]string) *dtos.DataPacket {
+		return &dtos.DataPacket{UserID: "1", CommandName: utils.CommandNames.VerifyComplete, MetaData: metaData}
+	}
+
+	t.Run("should grant the verified role and edit the verify reply", func(t *testing.T) {
+		config.AppConfig.SYNC_VERIFIED_NICK = false
+		entries := captureAudit(t)
+		session := &mockVerifiedSession{}
+		handler := &CommandHandler{discord: session, discordMessage: newPacket(map[string]string{"applicationId": "app", "token": "token"})}
+		assert.NoError(t, handler.verifyComplete())
+		assert.Equal(t, []string{"40"}, session.roles)
+		assert.Equal(t, i18n.T(i18n.DefaultLocale, i18n.VerifyCompleted), session.edited)
+		assert.Empty(t, session.dmContent)
+		assert.Empty(t, session.nickname)
+		assert.Len(t, *entries, 2)
+		assert.Equal(t, audit.ActionRoleAdd, (*entries)[0].Action)
+		assert.Equal(t, "job:verifyComplete", (*entries)[0].Actor)
+		assert.Equal(t, audit.ActionMessageEdit, (*entries)[1].Action)
+	})
+
+	t.Run("should grant the role in the guild of the packet, else in GUILD_ID", func(t *testing.T) {
+		session := &mockVerifiedSession{}
+		packet := newPacket(map[string]string{})
+		packet.GuildID = "876543210987654321"
+		assert.NoError(t, (&CommandHandler{discord: session, discordMessage: packet}).verifyComplete())
+		assert.NoError(t, (&CommandHandler{discord: session, discordMessage: newPacket(map[string]string{})}).verifyComplete())
+		assert.Equal(t, []string{"876543210987654321", config.AppConfig.GUILD_ID}, session.guilds)
+	})
+
+	t.Run("should send a direct message when no interaction is referenced", func(t *testing.T) {
+		session := &mockVerifiedSession{}
+		handler := &CommandHandler{discord: session, discordMessage: newPacket(map[string]string{})}
+		assert.NoError(t, handler.verifyComplete())
+		assert.Equal(t, "dm-1", session.dmChannel)
+		assert.Equal(t, i18n.T(i18n.DefaultLocale, i18n.VerifyCompleted), session.dmContent)
+	})
+
+	t.Run("should fall back to a direct message when the reply cannot be edited", func(t *testing.T) {
+		session := &mockVerifiedSession{editErr: errors.New("unknown webhook")}
+		handler := &CommandHandler{discord: session, discordMessage: newPacket(map[string]string{"applicationId": "app", "token": "token"})}
+		assert.NoError(t, handler.verifyComplete())
+		assert.Equal(t, i18n.T(i18n.DefaultLocale, i18n.VerifyCompleted), session.dmContent)
+	})
+
+	t.Run("should not retry the job when the direct message cannot be sent", func(t *testing.T) {
+		session := &mockVerifiedSession{dmErr: errors.New("cannot send messages to this user")}
+		handler := &CommandHandler{discord: session, discordMessage: newPacket(map[string]string{})}
+		assert.NoError(t, handler.verifyComplete())
+		assert.Equal(t, []string{"40"}, session.roles)
+	})
+
+	t.Run("should confirm in the locale of the user", func(t *testing.T) {
+		session := &mockVerifiedSession{}
+		handler := &CommandHandler{discord: session, discordMessage: newPacket(map[string]string{"locale": string(discordgo.Hindi)})}
+		assert.NoError(t, handler.verifyComplete())
+		assert.Equal(t, i18n.T(discordgo.Hindi, i18n.VerifyCompleted), session.dmContent)
+	})
+
+	t.Run("should skip the role when none is configured", func(t *testing.T) {
+		config.AppConfig.VERIFIED_ROLE_ID = ""
+		defer func() { config.AppConfig.VERIFIED_ROLE_ID = "40" }()
+		session := &mockVerifiedSession{}
+		handler := &CommandHandler{discord: session, discordMessage: newPacket(map[string]string{})}
+		assert.NoError(t, handler.verifyComplete())
+		assert.Empty(t, session.roles)
+	})
+
+	t.Run("should sync the nickname and keep the listening decoration", func(t *testing.T) {
+		config.AppConfig.SYNC_VERIFIED_NICK = true
+		defer func() { config.AppConfig.SYNC_VERIFIED_NICK = false }()
+		session := &mockVerifiedSession{member: &discordgo.Member{Nick: utils.NICKNAME_PREFIX + "old" + utils.NICKNAME_SUFFIX}}
+		handler := &CommandHandler{discord: session, discordMessage: newPacket(map[string]string{"userName": "joy"})}
+		assert.NoError(t, handler.verifyComplete())
+		assert.Equal(t, utils.NICKNAME_PREFIX+"joy"+utils.NICKNAME_SUFFIX, session.nickname)
+	})
+
+	t.Run("should return error so the job is retried when the role cannot be granted", func(t *testing.T) {
+		session := &mockVerifiedSession{roleErr: errors.New("missing permissions")}
+		handler := &CommandHandler{discord: session, discordMessage: newPacket(map[string]string{})}
+		assert.Error(t, handler.verifyComplete())
+		assert.Empty(t, session.dmContent)
+	})
+
+	t.Run("should return error if the discord client is not initialized", func(t *testing.T) {
+		handler := &CommandHandler{discordMessage: newPacket(map[string]string{})}
+		assert.ErrorIs(t, handler.verifyComplete(), ErrDiscordClientNotInitialized)
+	})
+}
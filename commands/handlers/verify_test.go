@@ -8,9 +8,11 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 	"github.com/Real-Dev-Squad/discord-service/config"
 	"github.com/Real-Dev-Squad/discord-service/dtos"
+	"github.com/Real-Dev-Squad/discord-service/i18n"
 	"github.com/bwmarrin/discordgo"
 	"github.com/stretchr/testify/assert"
 )
@@ -27,10 +29,6 @@ func (m *mockDiscordSession) WebhookMessageEdit(webhookID, token, messageID stri
 	return nil, nil
 }
 
-func (m *mockDiscordSession) Close() error {
-	return nil
-}
-
 type mockFailingDiscordSession struct {
 	*discordgo.Session
 }
@@ -39,18 +37,6 @@ func (m *mockFailingDiscordSession) WebhookMessageEdit(webhookID, token, message
 	return nil, errors.New("webhook error")
 }
 
-type mockFailingDiscordSessionCloser struct {
-	*discordgo.Session
-}
-
-func (m *mockFailingDiscordSessionCloser) WebhookMessageEdit(webhookID, token, messageID string, data *discordgo.WebhookEdit, options ...discordgo.RequestOption) (*discordgo.Message, error) {
-	return nil, nil
-}
-
-func (m *mockFailingDiscordSessionCloser) Close() error {
-	return errors.New("close error")
-}
-
 func generateTestPrivateKey(t *testing.T) *rsa.PrivateKey {
 	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
 	assert.NoError(t, err)
@@ -75,9 +61,8 @@ func TestVerify(t *testing.T) {
 	privateKey := generateTestPrivateKey(t)
 	pemPrivateKey := pemEncodePrivateKey(privateKey)
 
-	originalCreateSession := CreateSession
 	originalBotPrivateKey := config.AppConfig.BOT_PRIVATE_KEY
-	config.AppConfig.BOT_PRIVATE_KEY = pemPrivateKey
+	config.AppConfig.BOT_PRIVATE_KEY = config.NewSecret(pemPrivateKey)
 
 	t.Run("should return error when fails to generate unique token", func(t *testing.T) {
 	   uniqueToken := &mockFailingUniqueToken{}
@@ -88,7 +73,7 @@ func TestVerify(t *testing.T) {
     })
 
 	t.Run("should return error when fails to parse private key string to rsa private key", func(t *testing.T) {
-		config.AppConfig.BOT_PRIVATE_KEY = "<invalid-key>"
+		config.AppConfig.BOT_PRIVATE_KEY = config.NewSecret("<invalid-key>")
 		t.Cleanup(func() {
 			config.AppConfig.BOT_PRIVATE_KEY = originalBotPrivateKey
 		})
@@ -101,7 +86,7 @@ func TestVerify(t *testing.T) {
 	})
 
 	t.Run("should return error when fails to create http request", func(t *testing.T) {
-		config.AppConfig.BOT_PRIVATE_KEY = pemPrivateKey
+		config.AppConfig.BOT_PRIVATE_KEY = config.NewSecret(pemPrivateKey)
 		config.AppConfig.RDS_BASE_API_URL = "http://localhost:1234\x7f"
 		handler := &CommandHandler{
 			discordMessage: &dtos.DataPacket{},
@@ -112,7 +97,7 @@ func TestVerify(t *testing.T) {
 	})
 
 	t.Run("should return error when fails to send request to RDS Backend API", func(t *testing.T) {
-		config.AppConfig.BOT_PRIVATE_KEY = pemPrivateKey
+		config.AppConfig.BOT_PRIVATE_KEY = config.NewSecret(pemPrivateKey)
 		config.AppConfig.RDS_BASE_API_URL = "http://localhost:12345"
 		handler := &CommandHandler{
 			discordMessage: &dtos.DataPacket{},
@@ -122,93 +107,72 @@ func TestVerify(t *testing.T) {
 		assert.Contains(t, err.Error(), "error sending request to RDS Backend API")
 	})
 
-	t.Run("should return error when fails to create session", func(t *testing.T) {
-		config.AppConfig.BOT_PRIVATE_KEY = pemPrivateKey
+	t.Run("should return error when the discord client is not initialized", func(t *testing.T) {
+		config.AppConfig.BOT_PRIVATE_KEY = config.NewSecret(pemPrivateKey)
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusOK)
 		}))
 		defer server.Close()
 
 		config.AppConfig.RDS_BASE_API_URL = server.URL
-		CreateSession = func() (DiscordSessionWrapper, error) {
-			return nil, errors.New("session error")
-		}
-		t.Cleanup(func() {
-			CreateSession = originalCreateSession
-		})
-
 		handler := &CommandHandler{
 			discordMessage: &dtos.DataPacket{},
 		}
 
 		err := handler.verify()
 		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "error creating session")
+		assert.Contains(t, err.Error(), "error getting discord client")
 	})
 
 	t.Run("should return error when fails to edit original message", func(t *testing.T) {
-		config.AppConfig.BOT_PRIVATE_KEY = pemPrivateKey
+		config.AppConfig.BOT_PRIVATE_KEY = config.NewSecret(pemPrivateKey)
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusOK)
 		}))
 		defer server.Close()
 		config.AppConfig.RDS_BASE_API_URL = server.URL
 
-		CreateSession = func() (DiscordSessionWrapper, error) {
-			return &mockFailingDiscordSession{}, nil
-		}
-		t.Cleanup(func() {
-			CreateSession = originalCreateSession
-		})
 
-		handler := &CommandHandler{discordMessage: &dtos.DataPacket{}}
+		handler := &CommandHandler{discordMessage: &dtos.DataPacket{}, discord: &mockFailingDiscordSession{}}
 		err := handler.verify()
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "error editing original message for application")
 	})
 
-	t.Run("should return error when fails to close session", func(t *testing.T) {
-		config.AppConfig.BOT_PRIVATE_KEY = pemPrivateKey
+	t.Run("should not return error when succeeds", func(t *testing.T) {
+		config.AppConfig.BOT_PRIVATE_KEY = config.NewSecret(pemPrivateKey)
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusOK)
 		}))
 		defer server.Close()
 		config.AppConfig.RDS_BASE_API_URL = server.URL
 
-		CreateSession = func() (DiscordSessionWrapper, error) {
-			return &mockFailingDiscordSessionCloser{}, nil
-		}
-		t.Cleanup(func() {
-			CreateSession = originalCreateSession
-		})
-		handler := &CommandHandler{discordMessage: &dtos.DataPacket{}}
+
+		handler := &CommandHandler{discordMessage: &dtos.DataPacket{
+			MetaData: map[string]string{
+				"dev": "true",
+			},
+		}, discord: &mockDiscordSession{}}
+
 		err := handler.verify()
-		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "error closing session")
+		assert.NoError(t, err)
 	})
 
-	t.Run("should not return error when succeeds", func(t *testing.T) {
-		config.AppConfig.BOT_PRIVATE_KEY = pemPrivateKey
+	t.Run("should send the verification link in the locale of the user", func(t *testing.T) {
+		config.AppConfig.BOT_PRIVATE_KEY = config.NewSecret(pemPrivateKey)
 		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.WriteHeader(http.StatusOK)
 		}))
 		defer server.Close()
 		config.AppConfig.RDS_BASE_API_URL = server.URL
 
-		CreateSession = func() (DiscordSessionWrapper, error) {
-			return &mockDiscordSession{}, nil
-		}
-		t.Cleanup(func() {
-			CreateSession = originalCreateSession
-		})
-
+		message := ""
 		handler := &CommandHandler{discordMessage: &dtos.DataPacket{
-			MetaData: map[string]string{
-				"dev": "true",
-			},
-		}}
+			MetaData: map[string]string{"locale": string(discordgo.Hindi)},
+		}, discord: &mockDiscordSession{capturedMessage: &message}}
 
-		err := handler.verify()
-		assert.NoError(t, err)
+		assert.NoError(t, handler.verify())
+		assert.True(t, strings.HasPrefix(message, i18n.T(discordgo.Hindi, i18n.VerifyLink)+"\n"))
+		assert.True(t, strings.HasSuffix(message, "\n"+i18n.T(discordgo.Hindi, i18n.VerifyNote)))
 	})
 }
@@ -0,0 +1,102 @@
+package register
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// CommandDiff is what has to change for the registered commands to match the
+// commands defined in code.
+type CommandDiff struct {
+	Create    []*discordgo.ApplicationCommand
+	Update    []*discordgo.ApplicationCommand
+	Delete    []*discordgo.ApplicationCommand
+	Unchanged []*discordgo.ApplicationCommand
+}
+
+// IsEmpty reports whether the registered commands already match.
+func (d CommandDiff) IsEmpty() bool {
+	return len(d.Create) == 0 && len(d.Update) == 0 && len(d.Delete) == 0
+}
+
+// DiffCommands compares the desired commands with the registered ones by name.
+// Commands in Update and Delete carry the ID of the registered command.
+func DiffCommands(desired []*discordgo.ApplicationCommand, registered []*discordgo.ApplicationCommand) CommandDiff {
+	diff := CommandDiff{}
+	byName := make(map[string]*discordgo.ApplicationCommand, len(registered))
+	for _, command := range registered {
+		byName[command.Name] = command
+	}
+
+	seen := make(map[string]bool, len(desired))
+	for _, command := range desired {
+		seen[command.Name] = true
+		current, ok := byName[command.Name]
+		switch {
+		case !ok:
+			diff.Create = append(diff.Create, command)
+		case commandChanged(command, current):
+			update := *command
+			update.ID = current.ID
+			diff.Update = append(diff.Update, &update)
+		default:
+			diff.Unchanged = append(diff.Unchanged, current)
+		}
+	}
+
+	for _, command := range registered {
+		if !seen[command.Name] {
+			diff.Delete = append(diff.Delete, command)
+		}
+	}
+	return diff
+}
+
+// commandChanged compares the fields defined in code. Optional fields that are
+// not set in code are left to Discord's defaults and ignored.
+func commandChanged(desired *discordgo.ApplicationCommand, registered *discordgo.ApplicationCommand) bool {
+	if desired.Description != registered.Description || commandType(desired) != commandType(registered) {
+		return true
+	}
+	if !sameJSON(desired.Options, registered.Options) {
+		return true
+	}
+	if desired.DefaultMemberPermissions != nil && !reflect.DeepEqual(desired.DefaultMemberPermissions, registered.DefaultMemberPermissions) {
+		return true
+	}
+	if desired.DMPermission != nil && !reflect.DeepEqual(desired.DMPermission, registered.DMPermission) {
+		return true
+	}
+	if desired.NSFW != nil && !reflect.DeepEqual(desired.NSFW, registered.NSFW) {
+		return true
+	}
+	if desired.NameLocalizations != nil && !reflect.DeepEqual(desired.NameLocalizations, registered.NameLocalizations) {
+		return true
+	}
+	if desired.DescriptionLocalizations != nil && !reflect.DeepEqual(desired.DescriptionLocalizations, registered.DescriptionLocalizations) {
+		return true
+	}
+	return false
+}
+
+// commandType treats an unset type as a chat input command, which is what
+// Discord registers it as.
+func commandType(command *discordgo.ApplicationCommand) discordgo.ApplicationCommandType {
+	if command.Type == 0 {
+		return discordgo.ChatApplicationCommand
+	}
+	return command.Type
+}
+
+// sameJSON compares options the way Discord sees them, so that an empty list
+// and a missing one are equal.
+func sameJSON(a, b []*discordgo.ApplicationCommandOption) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	aJSON, errA := json.Marshal(a)
+	bJSON, errB := json.Marshal(b)
+	return errA == nil && errB == nil && string(aJSON) == string(bJSON)
+}
@@ -0,0 +1,59 @@
+package register
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiffCommands(t *testing.T) {
+	hello := &discordgo.ApplicationCommand{Name: "hello", Description: "Greets back with hello!"}
+	listening := &discordgo.ApplicationCommand{
+		Name:        "listening",
+		Description: "Mark user as listening",
+		Options: []*discordgo.ApplicationCommandOption{
+			{Name: "value", Description: "to enable or disable the listening mode", Type: discordgo.ApplicationCommandOptionBoolean, Required: true},
+		},
+	}
+
+	t.Run("should create commands that are not registered", func(t *testing.T) {
+		diff := DiffCommands([]*discordgo.ApplicationCommand{hello}, nil)
+		assert.Equal(t, []*discordgo.ApplicationCommand{hello}, diff.Create)
+		assert.False(t, diff.IsEmpty())
+	})
+
+	t.Run("should ignore fields Discord fills in on registration", func(t *testing.T) {
+		dmPermission := true
+		registered := &discordgo.ApplicationCommand{
+			ID:            "1",
+			ApplicationID: "2",
+			Version:       "3",
+			Type:          discordgo.ChatApplicationCommand,
+			Name:          "hello",
+			Description:   "Greets back with hello!",
+			DMPermission:  &dmPermission,
+		}
+		diff := DiffCommands([]*discordgo.ApplicationCommand{hello}, []*discordgo.ApplicationCommand{registered})
+		assert.True(t, diff.IsEmpty())
+		assert.Equal(t, []*discordgo.ApplicationCommand{registered}, diff.Unchanged)
+	})
+
+	t.Run("should update commands whose options changed", func(t *testing.T) {
+		registered := *listening
+		registered.ID = "1"
+		registered.Options = []*discordgo.ApplicationCommandOption{
+			{Name: "value", Description: "to enable or disable the listening mode", Type: discordgo.ApplicationCommandOptionBoolean},
+		}
+		diff := DiffCommands([]*discordgo.ApplicationCommand{listening}, []*discordgo.ApplicationCommand{&registered})
+		assert.Len(t, diff.Update, 1)
+		assert.Equal(t, "1", diff.Update[0].ID)
+		assert.Equal(t, "", listening.ID)
+	})
+
+	t.Run("should delete commands that are no longer defined", func(t *testing.T) {
+		stale := &discordgo.ApplicationCommand{ID: "1", Name: "stale"}
+		diff := DiffCommands([]*discordgo.ApplicationCommand{}, []*discordgo.ApplicationCommand{stale})
+		assert.Equal(t, []*discordgo.ApplicationCommand{stale}, diff.Delete)
+	})
+}
@@ -1,8 +1,12 @@
 package register
 
 import (
+	"errors"
+	"fmt"
+
 	constants "github.com/Real-Dev-Squad/discord-service/commands/constants"
 	"github.com/Real-Dev-Squad/discord-service/config"
+	"github.com/Real-Dev-Squad/discord-service/discord"
 	"github.com/Real-Dev-Squad/discord-service/models"
 	"github.com/bwmarrin/discordgo"
 	"github.com/sirupsen/logrus"
@@ -10,32 +14,239 @@ import (
 
 var NewDiscord = discordgo.New
 
-func SetupRegister() {
-	session, err := NewDiscord("Bot " + config.AppConfig.BOT_TOKEN)
+// Connect creates a REST-only session for managing the bot's commands. No
+// gateway connection is opened, so it can run from CI. The caller closes it.
+var Connect = func() (models.SessionInterface, error) {
+	session, err := NewDiscord("Bot " + config.AppConfig.BOT_TOKEN.Value())
 	if err != nil {
-		logrus.Panic("Cannot create a new Discord session ", err)
+		return nil, fmt.Errorf("cannot create a new Discord session: %v", err)
 	}
+	session.Client.Transport = discord.NewRateLimitTransport(session.Client.Transport)
+	session.Client.Timeout = config.AppConfig.DISCORD_REQUEST_TIMEOUT
+	session.ShouldRetryOnRateLimit = false
+	return &models.SessionWrapper{Session: session}, nil
+}
 
-	session.AddHandler(func(s *discordgo.Session, r *discordgo.Ready) {
-		logrus.Info("Logged in as: ", session.State.User.Username, session.State.User.Discriminator)
-	})
-	sessionWrapper := &models.SessionWrapper{Session: session}
-	RegisterCommands(sessionWrapper)
+// ApplicationID returns APPLICATION_ID, or looks up the application the bot
+// token belongs to when it is not set.
+func ApplicationID(session models.SessionInterface) (string, error) {
+	if config.AppConfig.APPLICATION_ID != "" {
+		return config.AppConfig.APPLICATION_ID, nil
+	}
+	application, err := session.CurrentApplication()
+	if err != nil {
+		return "", fmt.Errorf("cannot look up the application, set APPLICATION_ID to skip the lookup: %v", err)
+	}
+	return application.ID, nil
 }
 
-var RegisterCommands = func(openSession models.SessionInterface) {
-	err := openSession.Open()
-	defer openSession.Close()
+// ScopedCommands are the commands registered globally, when GuildID is empty,
+// or in one guild.
+type ScopedCommands struct {
+	GuildID  string
+	Commands []*discordgo.ApplicationCommand
+}
+
+// ScopedDiff is the diff of the commands registered globally, when GuildID is
+// empty, or in one guild.
+type ScopedDiff struct {
+	GuildID string
+	CommandDiff
+}
 
+// targets lists where commands are registered: globally, in every guild of
+// COMMAND_GUILD_IDS and in DEV_GUILD_ID. Every target is reconciled, even when
+// no command is meant for it, so that stale commands get deleted.
+func targets() []string {
+	result := []string{""}
+	seen := map[string]bool{"": true}
+	for _, guildID := range append(append([]string{}, config.AppConfig.COMMAND_GUILD_IDS...), config.AppConfig.DEV_GUILD_ID) {
+		if !seen[guildID] {
+			seen[guildID] = true
+			result = append(result, guildID)
+		}
+	}
+	return result
+}
+
+// desiredCommands returns the commands whose scope includes target.
+func desiredCommands(target string) []*discordgo.ApplicationCommand {
+	commands := []*discordgo.ApplicationCommand{}
+	for _, command := range constants.Commands {
+		for _, commandTarget := range constants.Targets(command) {
+			if commandTarget == target {
+				commands = append(commands, command.ApplicationCommand)
+				break
+			}
+		}
+	}
+	return commands
+}
+
+func warnUnregistrable() {
+	for _, command := range constants.Commands {
+		if len(constants.Targets(command)) == 0 {
+			logrus.Warnf("Command %s is dev-guild-only but DEV_GUILD_ID is not set, it will not be registered", command.Name)
+		}
+	}
+}
+
+// ListCommands returns the commands registered globally and in every guild
+// commands are registered in.
+func ListCommands(session models.SessionInterface) ([]ScopedCommands, error) {
+	applicationID, err := ApplicationID(session)
 	if err != nil {
-		logrus.Panic("Cannot open the session ", err)
+		return nil, err
 	}
+	return listCommands(session, applicationID)
+}
 
-	for _, v := range constants.Commands {
-		_, err := openSession.ApplicationCommandCreate(openSession.GetUerId(), config.AppConfig.GUILD_ID, v)
+func listCommands(session models.SessionInterface, applicationID string) ([]ScopedCommands, error) {
+	result := []ScopedCommands{}
+	for _, target := range targets() {
+		registered, err := session.ApplicationCommands(applicationID, target)
 		if err != nil {
-			logrus.Panic("Cannot create ", v.Name, "command: ", err)
+			return result, fmt.Errorf("cannot list registered commands of %s: %v", ScopeName(target), err)
+		}
+		result = append(result, ScopedCommands{GuildID: target, Commands: registered})
+	}
+	return result, nil
+}
+
+// PlanCommands returns what RegisterCommands would change in every scope.
+func PlanCommands(session models.SessionInterface) ([]ScopedDiff, error) {
+	applicationID, err := ApplicationID(session)
+	if err != nil {
+		return nil, err
+	}
+	return planCommands(session, applicationID)
+}
+
+func planCommands(session models.SessionInterface, applicationID string) ([]ScopedDiff, error) {
+	warnUnregistrable()
+	scoped, err := listCommands(session, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	diffs := []ScopedDiff{}
+	for _, registered := range scoped {
+		diff := DiffCommands(desiredCommands(registered.GuildID), registered.Commands)
+		diffs = append(diffs, ScopedDiff{GuildID: registered.GuildID, CommandDiff: diff})
+	}
+	return diffs, nil
+}
+
+// RegisterCommands makes the registered commands match constants.Commands,
+// globally and in every guild they are scoped to. Only commands that are new or
+// changed are sent to Discord, and commands that are no longer defined are
+// deleted, so running it again changes nothing. With dryRun set the changes
+// are only worked out and logged.
+func RegisterCommands(session models.SessionInterface, dryRun bool) ([]ScopedDiff, error) {
+	applicationID, err := ApplicationID(session)
+	if err != nil {
+		return nil, err
+	}
+	diffs, err := planCommands(session, applicationID)
+	if err != nil {
+		return diffs, err
+	}
+	var errs []error
+	for _, diff := range diffs {
+		if !dryRun {
+			if err := applyDiff(session, applicationID, diff.GuildID, diff.CommandDiff); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		logSummary(diff, dryRun)
+	}
+	return diffs, errors.Join(errs...)
+}
+
+// DeleteCommands deletes the registered commands with the given names from
+// every scope and returns the names it deleted.
+func DeleteCommands(session models.SessionInterface, names []string) ([]string, error) {
+	applicationID, err := ApplicationID(session)
+	if err != nil {
+		return nil, err
+	}
+	scoped, err := listCommands(session, applicationID)
+	if err != nil {
+		return nil, err
+	}
+	wanted := make(map[string]bool, len(names))
+	for _, name := range names {
+		wanted[name] = true
+	}
+
+	deleted := []string{}
+	found := map[string]bool{}
+	var errs []error
+	for _, registered := range scoped {
+		for _, command := range registered.Commands {
+			if !wanted[command.Name] {
+				continue
+			}
+			found[command.Name] = true
+			if err := session.ApplicationCommandDelete(applicationID, registered.GuildID, command.ID); err != nil {
+				errs = append(errs, fmt.Errorf("cannot delete %s command from %s: %v", command.Name, ScopeName(registered.GuildID), err))
+				continue
+			}
+			deleted = append(deleted, command.Name)
+		}
+	}
+	for _, name := range names {
+		if !found[name] {
+			errs = append(errs, fmt.Errorf("command %s is not registered", name))
 		}
 	}
-	logrus.Info("Successfully registered commands")
+	return deleted, errors.Join(errs...)
+}
+
+// ScopeName describes where commands of guildID are registered.
+func ScopeName(guildID string) string {
+	if guildID == "" {
+		return "global"
+	}
+	return "guild " + guildID
+}
+
+// applyDiff makes every change in diff, carrying on past failures so that one
+// bad command does not hold back the others.
+func applyDiff(session models.SessionInterface, applicationID, guildID string, diff CommandDiff) error {
+	var errs []error
+	for _, command := range diff.Create {
+		if _, err := session.ApplicationCommandCreate(applicationID, guildID, command); err != nil {
+			errs = append(errs, fmt.Errorf("cannot create %s command: %v", command.Name, err))
+		}
+	}
+	for _, command := range diff.Update {
+		if _, err := session.ApplicationCommandEdit(applicationID, guildID, command.ID, command); err != nil {
+			errs = append(errs, fmt.Errorf("cannot update %s command: %v", command.Name, err))
+		}
+	}
+	for _, command := range diff.Delete {
+		if err := session.ApplicationCommandDelete(applicationID, guildID, command.ID); err != nil {
+			errs = append(errs, fmt.Errorf("cannot delete %s command: %v", command.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func CommandNames(commands []*discordgo.ApplicationCommand) []string {
+	names := make([]string, 0, len(commands))
+	for _, command := range commands {
+		names = append(names, command.Name)
+	}
+	return names
+}
+
+func logSummary(diff ScopedDiff, dryRun bool) {
+	logrus.WithFields(logrus.Fields{
+		"scope":     ScopeName(diff.GuildID),
+		"added":     CommandNames(diff.Create),
+		"changed":   CommandNames(diff.Update),
+		"removed":   CommandNames(diff.Delete),
+		"unchanged": len(diff.Unchanged),
+		"dryRun":    dryRun,
+	}).Info("Registered commands")
 }
@@ -1,46 +1,92 @@
 package register
 
 import (
+	"net/http"
 	"testing"
 
+	constants "github.com/Real-Dev-Squad/discord-service/commands/constants"
+	"github.com/Real-Dev-Squad/discord-service/config"
+	"github.com/Real-Dev-Squad/discord-service/models"
 	_ "github.com/Real-Dev-Squad/discord-service/tests/helpers"
+	"github.com/Real-Dev-Squad/discord-service/utils"
 	"github.com/bwmarrin/discordgo"
 	"github.com/stretchr/testify/assert"
 )
 
-func TestInit(t *testing.T) {
+func TestConnect(t *testing.T) {
 
-	t.Run("should panic when SetupConnection returns an error", func(t *testing.T) {
+	t.Run("should return error when SetupConnection returns an error", func(t *testing.T) {
 		originalNewDiscord := NewDiscord
 		defer func() { NewDiscord = originalNewDiscord }()
 		NewDiscord = func(token string) (s *discordgo.Session, err error) {
 			return nil, assert.AnError
 		}
-		assert.Panics(t, SetupRegister)
+		_, err := Connect()
+		assert.Error(t, err)
 	})
-	t.Run("should call AddHandler method of session if SetupConnection succeeds", func(t *testing.T) {
+	t.Run("should return a REST-only session without opening a gateway connection", func(t *testing.T) {
 		originalNewDiscord := NewDiscord
 		defer func() { NewDiscord = originalNewDiscord }()
 		NewDiscord = func(token string) (s *discordgo.Session, err error) {
-			mockSession := &discordgo.Session{
-				State: &discordgo.State{},
-			}
-			return mockSession, nil
+			return &discordgo.Session{Client: &http.Client{}, ShouldRetryOnRateLimit: true}, nil
 		}
-		assert.Panics(t, SetupRegister)
+		session, err := Connect()
+		assert.NoError(t, err)
+		wrapper := session.(*models.SessionWrapper)
+		assert.Nil(t, wrapper.Session.State)
+		assert.False(t, wrapper.Session.ShouldRetryOnRateLimit)
+		assert.NoError(t, session.Close())
 	})
 }
 
+func TestApplicationID(t *testing.T) {
+	original := config.AppConfig.APPLICATION_ID
+	defer func() { config.AppConfig.APPLICATION_ID = original }()
+
+	t.Run("should use APPLICATION_ID without a lookup", func(t *testing.T) {
+		config.AppConfig.APPLICATION_ID = "configured"
+		mockSess := &mockSession{}
+		applicationID, err := ApplicationID(mockSess)
+		assert.NoError(t, err)
+		assert.Equal(t, "configured", applicationID)
+		assert.False(t, mockSess.lookupCalled)
+	})
+	t.Run("should look up the application when APPLICATION_ID is not set", func(t *testing.T) {
+		config.AppConfig.APPLICATION_ID = ""
+		mockSess := &mockSession{}
+		applicationID, err := ApplicationID(mockSess)
+		assert.NoError(t, err)
+		assert.Equal(t, "application", applicationID)
+		assert.True(t, mockSess.lookupCalled)
+	})
+	t.Run("should return error when the lookup fails", func(t *testing.T) {
+		config.AppConfig.APPLICATION_ID = ""
+		_, err := ApplicationID(&mockSession{lookupError: assert.AnError})
+		assert.ErrorContains(t, err, "APPLICATION_ID")
+	})
+}
+
+// mockSession records every change as "<guildID>/<name or ID>", with an empty
+// guildID for global commands.
 type mockSession struct {
-	openError                error
 	commandError             error
+	listError                error
+	registered               map[string][]*discordgo.ApplicationCommand
+	created                  []string
+	edited                   []string
+	deleted                  []string
+	lookupError              error
+	applicationIDs           map[string]bool
 	applicationCommandCalled bool
 	closeCalled              bool
-	getUserIdCalled          bool
+	lookupCalled             bool
 }
 
-func (m *mockSession) Open() error {
-	return m.openError
+func (m *mockSession) used(applicationID string) {
+	if m.applicationIDs == nil {
+		m.applicationIDs = map[string]bool{}
+	}
+	m.applicationIDs[applicationID] = true
 }
 
 func (m *mockSession) Close() error {
@@ -49,52 +95,183 @@ func (m *mockSession) Close() error {
 }
 
 func (m *mockSession) ApplicationCommandCreate(applicationID, guildID string, command *discordgo.ApplicationCommand) (*discordgo.ApplicationCommand, error) {
+	m.used(applicationID)
 	if m.commandError == nil {
 		m.applicationCommandCalled = true
+		m.created = append(m.created, guildID+"/"+command.Name)
 	}
 	return nil, m.commandError
 }
 
-func (m *mockSession) GetUerId() string {
-	m.getUserIdCalled = true
-	return ""
+func (m *mockSession) ApplicationCommands(applicationID, guildID string) ([]*discordgo.ApplicationCommand, error) {
+	m.used(applicationID)
+	return m.registered[guildID], m.listError
+}
+
+func (m *mockSession) ApplicationCommandEdit(applicationID, guildID, commandID string, command *discordgo.ApplicationCommand) (*discordgo.ApplicationCommand, error) {
+	m.used(applicationID)
+	m.edited = append(m.edited, guildID+"/"+commandID)
+	return command, m.commandError
+}
+
+func (m *mockSession) ApplicationCommandDelete(applicationID, guildID, commandID string) error {
+	m.used(applicationID)
+	m.deleted = append(m.deleted, guildID+"/"+commandID)
+	return m.commandError
+}
+
+func (m *mockSession) CurrentApplication() (*discordgo.Application, error) {
+	m.lookupCalled = true
+	if m.lookupError != nil {
+		return nil, m.lookupError
+	}
+	return &discordgo.Application{ID: "application"}, nil
+}
+
+// registeredCommands returns the commands as Discord would list them once
+// they are all registered.
+func registeredCommands() map[string][]*discordgo.ApplicationCommand {
+	registered := map[string][]*discordgo.ApplicationCommand{}
+	for _, target := range targets() {
+		for _, command := range desiredCommands(target) {
+			listed := *command
+			listed.ID = command.Name + "-id"
+			listed.Type = discordgo.ChatApplicationCommand
+			registered[target] = append(registered[target], &listed)
+		}
+	}
+	return registered
+}
+
+func withDevGuild(t *testing.T, guildID string) {
+	original := config.AppConfig.DEV_GUILD_ID
+	t.Cleanup(func() { config.AppConfig.DEV_GUILD_ID = original })
+	config.AppConfig.DEV_GUILD_ID = guildID
+}
+
+func created(diffs []ScopedDiff) int {
+	count := 0
+	for _, diff := range diffs {
+		count += len(diff.Create)
+	}
+	return count
+}
+
+func TestTargets(t *testing.T) {
+	t.Run("should register globally and in the command guilds", func(t *testing.T) {
+		assert.Equal(t, []string{"", config.AppConfig.GUILD_ID}, targets())
+	})
+	t.Run("should add the dev guild once", func(t *testing.T) {
+		withDevGuild(t, "dev-guild")
+		assert.Equal(t, []string{"", config.AppConfig.GUILD_ID, "dev-guild"}, targets())
+		withDevGuild(t, config.AppConfig.GUILD_ID)
+		assert.Equal(t, []string{"", config.AppConfig.GUILD_ID}, targets())
+	})
+	t.Run("should only desire the commands scoped to the target", func(t *testing.T) {
+		assert.Equal(t, []string{utils.CommandNames.Hello}, CommandNames(desiredCommands("")))
+		assert.NotContains(t, CommandNames(desiredCommands(config.AppConfig.GUILD_ID)), utils.CommandNames.Hello)
+		assert.Empty(t, desiredCommands("unknown-guild"))
+	})
 }
 
 func TestRegisterCommands(t *testing.T) {
-	t.Run("should not panic when Open() returns no error", func(t *testing.T) {
-		mockSess := &mockSession{openError: nil, commandError: nil}
-		assert.NotPanics(t, func() {
-			RegisterCommands(mockSess)
-		}, "RegisterCommands should not panic when Open is successful")
-
-	})
-	t.Run("should panic when Open() returns an error", func(t *testing.T) {
-		mockSess := &mockSession{openError: assert.AnError, commandError: nil}
-		assert.Panics(t, func() {
-			RegisterCommands(mockSess)
-		}, "RegisterCommands should panic when Open returns an error")
-	})
-	t.Run("should panic when openSession.ApplicationCommandCreate() returns an error", func(t *testing.T) {
-		mockSess := &mockSession{openError: nil, commandError: assert.AnError}
-
-		assert.Panics(t, func() {
-			RegisterCommands(mockSess)
-		}, "RegisterCommands should panic when ApplicationCommandCreate returns an error")
-	})
-	t.Run("should panic when openSession.ApplicationCommandCreate() returns an error", func(t *testing.T) {
-		mockSess := &mockSession{openError: nil, commandError: assert.AnError}
-
-		assert.Panics(t, func() {
-			RegisterCommands(mockSess)
-		}, "RegisterCommands should panic when ApplicationCommandCreate returns an error")
-	})
-	t.Run("should call all methods when none of the methods returns no error", func(t *testing.T) {
-		mockSess := &mockSession{openError: nil, commandError: nil}
-		assert.NotPanics(t, func() {
-			RegisterCommands(mockSess)
-		})
+	t.Run("should return error when openSession.ApplicationCommandCreate() returns an error", func(t *testing.T) {
+		mockSess := &mockSession{commandError: assert.AnError}
+		_, err := RegisterCommands(mockSess, false)
+		assert.Error(t, err)
+	})
+	t.Run("should return error when the application cannot be looked up", func(t *testing.T) {
+		mockSess := &mockSession{lookupError: assert.AnError}
+		_, err := RegisterCommands(mockSess, false)
+		assert.Error(t, err)
+		assert.Empty(t, mockSess.applicationIDs)
+	})
+	t.Run("should return error when registered commands cannot be listed", func(t *testing.T) {
+		mockSess := &mockSession{listError: assert.AnError}
+		_, err := RegisterCommands(mockSess, false)
+		assert.Error(t, err)
+		assert.False(t, mockSess.applicationCommandCalled)
+	})
+	t.Run("should create every command in its scope when none is registered", func(t *testing.T) {
+		mockSess := &mockSession{}
+		diffs, err := RegisterCommands(mockSess, false)
+		assert.NoError(t, err)
 		assert.True(t, mockSess.applicationCommandCalled)
-		assert.True(t, mockSess.getUserIdCalled)
-		assert.True(t, mockSess.closeCalled)
+		assert.Equal(t, map[string]bool{"application": true}, mockSess.applicationIDs)
+		assert.Len(t, mockSess.created, len(constants.Commands))
+		assert.Equal(t, len(constants.Commands), created(diffs))
+		assert.Contains(t, mockSess.created, "/"+utils.CommandNames.Hello)
+		assert.Contains(t, mockSess.created, config.AppConfig.GUILD_ID+"/"+utils.CommandNames.Listening)
+	})
+	t.Run("should change nothing when the commands are already registered", func(t *testing.T) {
+		mockSess := &mockSession{registered: registeredCommands()}
+		diffs, err := RegisterCommands(mockSess, false)
+		assert.NoError(t, err)
+		for _, diff := range diffs {
+			assert.True(t, diff.IsEmpty())
+		}
+		assert.Empty(t, mockSess.created)
+		assert.Empty(t, mockSess.edited)
+		assert.Empty(t, mockSess.deleted)
+	})
+	t.Run("should update changed commands and delete stale ones", func(t *testing.T) {
+		registered := registeredCommands()
+		guild := config.AppConfig.GUILD_ID
+		registered[guild][0].Description = "an old description"
+		registered[guild] = append(registered[guild], &discordgo.ApplicationCommand{ID: "stale-id", Name: "stale"})
+		mockSess := &mockSession{registered: registered}
+		_, err := RegisterCommands(mockSess, false)
+		assert.NoError(t, err)
+		assert.Empty(t, mockSess.created)
+		assert.Equal(t, []string{guild + "/" + registered[guild][0].ID}, mockSess.edited)
+		assert.Equal(t, []string{guild + "/stale-id"}, mockSess.deleted)
+	})
+	t.Run("should move a command registered in the wrong scope", func(t *testing.T) {
+		registered := registeredCommands()
+		guild := config.AppConfig.GUILD_ID
+		registered[guild] = append(registered[guild], &discordgo.ApplicationCommand{ID: "hello-guild-id", Name: utils.CommandNames.Hello})
+		mockSess := &mockSession{registered: registered}
+		_, err := RegisterCommands(mockSess, false)
+		assert.NoError(t, err)
+		assert.Empty(t, mockSess.created)
+		assert.Equal(t, []string{guild + "/hello-guild-id"}, mockSess.deleted)
+	})
+	t.Run("should reconcile the dev guild when it is set", func(t *testing.T) {
+		withDevGuild(t, "dev-guild")
+		registered := registeredCommands()
+		registered["dev-guild"] = []*discordgo.ApplicationCommand{{ID: "stale-id", Name: "stale"}}
+		mockSess := &mockSession{registered: registered}
+		_, err := RegisterCommands(mockSess, false)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"dev-guild/stale-id"}, mockSess.deleted)
+	})
+	t.Run("should only work out the changes in a dry run", func(t *testing.T) {
+		registered := registeredCommands()
+		registered[""] = append(registered[""], &discordgo.ApplicationCommand{ID: "stale-id", Name: "stale"})
+		mockSess := &mockSession{registered: registered}
+		diffs, err := RegisterCommands(mockSess, true)
+		assert.NoError(t, err)
+		assert.Equal(t, "", diffs[0].GuildID)
+		assert.Equal(t, "stale", diffs[0].Delete[0].Name)
+		assert.Empty(t, mockSess.deleted)
+	})
+}
+
+func TestDeleteCommands(t *testing.T) {
+	t.Run("should delete the named commands from every scope", func(t *testing.T) {
+		registered := registeredCommands()
+		guild := config.AppConfig.GUILD_ID
+		registered[guild] = append(registered[guild], &discordgo.ApplicationCommand{ID: "hello-guild-id", Name: utils.CommandNames.Hello})
+		mockSess := &mockSession{registered: registered}
+		deleted, err := DeleteCommands(mockSess, []string{"hello"})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"hello", "hello"}, deleted)
+		assert.Equal(t, []string{"/hello-id", guild + "/hello-guild-id"}, mockSess.deleted)
+	})
+	t.Run("should return error for commands that are not registered", func(t *testing.T) {
+		mockSess := &mockSession{registered: registeredCommands()}
+		deleted, err := DeleteCommands(mockSess, []string{"hello", "unknown"})
+		assert.Error(t, err)
+		assert.Equal(t, []string{"hello"}, deleted)
 	})
 }
@@ -1,55 +1,417 @@
 package config
 
 import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/golang-jwt/jwt/v5"
 	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
 )
 
 type Config struct {
-	Port                  string
-	DISCORD_PUBLIC_KEY    string
-	GUILD_ID              string
-	BOT_TOKEN             string
-	QUEUE_URL             string
-	QUEUE_NAME            string
-	MAX_RETRIES           int
-	RDS_BASE_API_URL      string
-	MAIN_SITE_URL         string
-	BOT_PRIVATE_KEY       string
-	VERIFICATION_SITE_URL string
-}
-
-var AppConfig Config
-
-func init() {
+	Port                        string
+	ENV                         string
+	LOG_LEVEL                   logrus.Level
+	DISCORD_PUBLIC_KEY          string
+	GUILD_ID                    string
+	BOT_TOKEN                   Secret
+	QUEUE_URL                   Secret
+	QUEUE_NAME                  string
+	MAX_RETRIES                 int
+	RDS_BASE_API_URL            string
+	MAIN_SITE_URL               string
+	BOT_PRIVATE_KEY             Secret
+	VERIFICATION_SITE_URL       string
+	INTERACTION_MAX_AGE         time.Duration
+	GATEWAY_ENABLED             bool
+	RDS_PUBLIC_KEY              string
+	ROLE_MAPPINGS               map[string]string
+	VERIFIED_ROLE_ID            string
+	SYNC_VERIFIED_NICK          bool
+	NICKNAME_RECONCILE_INTERVAL time.Duration
+	COMMAND_GUILD_IDS           []string
+	DEV_GUILD_ID                string
+	COMMANDS_FILE               string
+	APPLICATION_ID              string
+	REGISTRATION_SCOPE          string
+	DISABLED_COMMANDS           CommandFlags
+	HTTP_READ_TIMEOUT           time.Duration
+	HTTP_WRITE_TIMEOUT          time.Duration // none by default, /queue replies once a job's retries are done
+	HTTP_IDLE_TIMEOUT           time.Duration
+	RDS_REQUEST_TIMEOUT         time.Duration
+	DISCORD_REQUEST_TIMEOUT     time.Duration
+	SHUTDOWN_TIMEOUT            time.Duration
+}
+
+// AppConfig is the configuration of the running service. It holds Defaults()
+// until Load() is called at startup, tests set the fields they need.
+var AppConfig = Defaults()
+
+// Defaults returns the configuration used for every variable that is not set,
+// with the defaults of the development profile.
+func Defaults() Config {
+	profile := Profiles[Development]
+	return Config{
+		Port:                    "8999",
+		ENV:                     Development,
+		LOG_LEVEL:               profile.LOG_LEVEL,
+		RDS_BASE_API_URL:        profile.RDS_BASE_API_URL,
+		MAIN_SITE_URL:           profile.MAIN_SITE_URL,
+		VERIFICATION_SITE_URL:   profile.VERIFICATION_SITE_URL,
+		REGISTRATION_SCOPE:      profile.REGISTRATION_SCOPE,
+		DISABLED_COMMANDS:       NewCommandFlags(),
+		BOT_TOKEN:               NewSecret(""),
+		QUEUE_URL:               NewSecret("amqp://localhost"),
+		BOT_PRIVATE_KEY:         NewSecret(""),
+		QUEUE_NAME:              "DISCORD_QUEUE",
+		MAX_RETRIES:             5,
+		INTERACTION_MAX_AGE:     time.Minute,
+		ROLE_MAPPINGS:           map[string]string{},
+		HTTP_READ_TIMEOUT:       10 * time.Second,
+		HTTP_IDLE_TIMEOUT:       time.Minute,
+		RDS_REQUEST_TIMEOUT:     10 * time.Second,
+		DISCORD_REQUEST_TIMEOUT: 20 * time.Second,
+		SHUTDOWN_TIMEOUT:        30 * time.Second,
+	}
+}
+
+// Load reads the configuration from the environment, and from a .env file when
+// there is one. Every variable can also be read from the file named by
+// <NAME>_FILE, as Docker and Kubernetes secrets are mounted. Every invalid or
+// missing variable is reported in one error.
+func Load() (Config, error) {
 	if err := godotenv.Load(); err != nil {
-		logrus.Error(err)
+		logrus.Debugf("No .env file loaded: %v", err)
 	} else {
 		logrus.Info("Loaded .env file successfully")
 	}
+	return loadFrom(os.Getenv)
+}
+
+// loadFrom reads the configuration with getenv, starting from Defaults() and
+// the profile selected by ENV.
+func loadFrom(getenv func(key string) string) (Config, error) {
+	l := &loader{getenv: getenv}
+	defaults := Defaults()
+	env := l.oneOf("ENV", Development, Development, Staging, Production)
+	profile := Profiles[env]
+	config := Config{
+		Port:                        l.port("PORT", defaults.Port),
+		ENV:                         env,
+		LOG_LEVEL:                   l.logLevel("LOG_LEVEL", profile.LOG_LEVEL),
+		DISCORD_PUBLIC_KEY:          l.publicKey("DISCORD_PUBLIC_KEY"),
+		GUILD_ID:                    l.snowflake("GUILD_ID", true),
+		BOT_TOKEN:                   NewSecret(l.required("BOT_TOKEN")),
+		QUEUE_URL:                   NewSecret(l.url("QUEUE_URL", defaults.QUEUE_URL.Value(), "amqp", "amqps")),
+		QUEUE_NAME:                  l.string("QUEUE_NAME", defaults.QUEUE_NAME),
+		MAX_RETRIES:                 l.int("MAX_RETRIES", defaults.MAX_RETRIES, 1),
+		RDS_BASE_API_URL:            l.url("RDS_BASE_API_URL", profile.RDS_BASE_API_URL, "http", "https"),
+		MAIN_SITE_URL:               l.url("MAIN_SITE_URL", profile.MAIN_SITE_URL, "http", "https"),
+		BOT_PRIVATE_KEY:             NewSecret(l.privateKey("BOT_PRIVATE_KEY")),
+		VERIFICATION_SITE_URL:       l.url("VERIFICATION_SITE_URL", profile.VERIFICATION_SITE_URL, "http", "https"),
+		INTERACTION_MAX_AGE:         l.duration("INTERACTION_MAX_AGE", defaults.INTERACTION_MAX_AGE, true),
+		GATEWAY_ENABLED:             l.bool("GATEWAY_ENABLED", false),
+		RDS_PUBLIC_KEY:              l.rdsPublicKey("RDS_PUBLIC_KEY"),
+		ROLE_MAPPINGS:               l.roleMappings("ROLE_MAPPINGS"),
+		VERIFIED_ROLE_ID:            l.snowflake("VERIFIED_ROLE_ID", false),
+		SYNC_VERIFIED_NICK:          l.bool("SYNC_VERIFIED_NICK", false),
+		NICKNAME_RECONCILE_INTERVAL: l.duration("NICKNAME_RECONCILE_INTERVAL", 0, false),
+		DEV_GUILD_ID:                l.snowflake("DEV_GUILD_ID", false),
+		COMMANDS_FILE:               l.string("COMMANDS_FILE", ""),
+		APPLICATION_ID:              l.snowflake("APPLICATION_ID", false),
+		REGISTRATION_SCOPE:          l.oneOf("REGISTRATION_SCOPE", profile.REGISTRATION_SCOPE, RegisterGuilds, RegisterDeclared, RegisterPublic),
+		DISABLED_COMMANDS:           parseCommandFlags(l.string("DISABLED_COMMANDS", "")),
+		HTTP_READ_TIMEOUT:           l.duration("HTTP_READ_TIMEOUT", defaults.HTTP_READ_TIMEOUT, true),
+		HTTP_WRITE_TIMEOUT:          l.duration("HTTP_WRITE_TIMEOUT", defaults.HTTP_WRITE_TIMEOUT, false),
+		HTTP_IDLE_TIMEOUT:           l.duration("HTTP_IDLE_TIMEOUT", defaults.HTTP_IDLE_TIMEOUT, true),
+		RDS_REQUEST_TIMEOUT:         l.duration("RDS_REQUEST_TIMEOUT", defaults.RDS_REQUEST_TIMEOUT, true),
+		DISCORD_REQUEST_TIMEOUT:     l.duration("DISCORD_REQUEST_TIMEOUT", defaults.DISCORD_REQUEST_TIMEOUT, true),
+		SHUTDOWN_TIMEOUT:            l.duration("SHUTDOWN_TIMEOUT", defaults.SHUTDOWN_TIMEOUT, true),
+	}
+	config.COMMAND_GUILD_IDS = l.snowflakes("COMMAND_GUILD_IDS", []string{config.GUILD_ID})
+
+	if len(l.errs) > 0 {
+		return config, fmt.Errorf("invalid configuration:\n%w", errors.Join(l.errs...))
+	}
+	return config, nil
+}
+
+// readFile reads the files named by <NAME>_FILE variables.
+var readFile = os.ReadFile
+
+// Reload loads the configuration again and swaps BOT_TOKEN, BOT_PRIVATE_KEY
+// and DISABLED_COMMANDS of AppConfig for the reloaded ones, so rotated secret files
+// and edited flag files take effect without a restart. Every other variable
+// keeps its value until the service restarts. It returns the names of the
+// variables that changed, and leaves AppConfig untouched when the new
+// configuration is invalid.
+func Reload() ([]string, error) {
+	reloaded, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	if reloaded.QUEUE_URL.Value() != AppConfig.QUEUE_URL.Value() {
+		logrus.Warn("QUEUE_URL changed, restart the service to connect to the new broker")
+	}
+	current := AppConfig.secrets()
+	changed := []string{}
+	for _, name := range reloadableSecrets {
+		if value := reloaded.secrets()[name].Value(); value != current[name].Value() {
+			current[name].set(value)
+			changed = append(changed, name)
+		}
+	}
+	if disabled := reloaded.DISABLED_COMMANDS.List(); !slices.Equal(disabled, AppConfig.DISABLED_COMMANDS.List()) {
+		AppConfig.DISABLED_COMMANDS.set(disabled)
+		changed = append(changed, "DISABLED_COMMANDS")
+	}
+	return changed, nil
+}
+
+// reloadableSecrets are the secrets Reload swaps. QUEUE_URL is not one of
+// them, the AMQP connection is only dialled once.
+var reloadableSecrets = []string{"BOT_TOKEN", "BOT_PRIVATE_KEY"}
+
+func (c Config) secrets() map[string]Secret {
+	return map[string]Secret{
+		"BOT_TOKEN":       c.BOT_TOKEN,
+		"BOT_PRIVATE_KEY": c.BOT_PRIVATE_KEY,
+	}
+}
+
+// snowflakePattern is a Discord ID.
+var snowflakePattern = regexp.MustCompile(`^[0-9]{17,20}$`)
+
+// loader reads variables and collects every problem instead of stopping at the
+// first one.
+type loader struct {
+	getenv func(key string) string
+	errs   []error
+}
+
+func (l *loader) fail(key string, format string, args ...any) {
+	l.errs = append(l.errs, fmt.Errorf("%s %s", key, fmt.Sprintf(format, args...)))
+}
+
+// string reads key, or the file named by key_FILE when that is set.
+func (l *loader) string(key string, fallback string) string {
+	value := strings.TrimSpace(l.getenv(key))
+	if path := strings.TrimSpace(l.getenv(key + "_FILE")); path != "" {
+		if value != "" {
+			l.fail(key, "and %s_FILE cannot both be set", key)
+			return fallback
+		}
+		data, err := readFile(path)
+		if err != nil {
+			l.fail(key+"_FILE", "cannot be read: %v", err)
+			return fallback
+		}
+		value = strings.TrimSpace(string(data))
+	}
+	if value != "" {
+		return value
+	}
+	return fallback
+}
+
+func (l *loader) required(key string) string {
+	value := l.string(key, "")
+	if value == "" {
+		l.fail(key, "is not set")
+	}
+	return value
+}
+
+func (l *loader) oneOf(key string, fallback string, values ...string) string {
+	value := l.string(key, fallback)
+	for _, allowed := range values {
+		if value == allowed {
+			return value
+		}
+	}
+	l.fail(key, "must be one of %s, got %q", strings.Join(values, ", "), value)
+	return fallback
+}
+
+func (l *loader) logLevel(key string, fallback logrus.Level) logrus.Level {
+	value := l.string(key, "")
+	if value == "" {
+		return fallback
+	}
+	level, err := logrus.ParseLevel(value)
+	if err != nil {
+		l.fail(key, "must be a log level such as debug, info or warn, got %q", value)
+		return fallback
+	}
+	return level
+}
+
+func (l *loader) int(key string, fallback int, min int) int {
+	value := l.string(key, "")
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < min {
+		l.fail(key, "must be a whole number of at least %d, got %q", min, value)
+		return fallback
+	}
+	return parsed
+}
 
-	AppConfig = Config{
-		Port:               loadEnv("PORT"),
-		QUEUE_URL:          loadEnv("QUEUE_URL"),
-		DISCORD_PUBLIC_KEY: loadEnv("DISCORD_PUBLIC_KEY"),
-		GUILD_ID:           loadEnv("GUILD_ID"),
-		BOT_TOKEN:          loadEnv("BOT_TOKEN"),
-		QUEUE_NAME:         loadEnv("QUEUE_NAME"),
-		MAX_RETRIES:        5,
-		RDS_BASE_API_URL:   loadEnv("RDS_BASE_API_URL"),
-		MAIN_SITE_URL:      loadEnv("MAIN_SITE_URL"),
-		BOT_PRIVATE_KEY:    loadEnv("BOT_PRIVATE_KEY"),
-		VERIFICATION_SITE_URL: loadEnv("VERIFICATION_SITE_URL"),
+func (l *loader) port(key string, fallback string) string {
+	value := l.string(key, fallback)
+	if port, err := strconv.Atoi(value); err != nil || port < 1 || port > 65535 {
+		l.fail(key, "must be a port between 1 and 65535, got %q", value)
+	}
+	return value
+}
+
+func (l *loader) bool(key string, fallback bool) bool {
+	value := l.string(key, "")
+	if value == "" {
+		return fallback
+	}
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		l.fail(key, "must be true or false, got %q", value)
+		return fallback
+	}
+	return parsed
+}
+
+// duration parses a duration such as "30s". Zero is only allowed when
+// positive is false, where it usually turns a feature off.
+func (l *loader) duration(key string, fallback time.Duration, positive bool) time.Duration {
+	value := l.string(key, "")
+	if value == "" {
+		return fallback
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		l.fail(key, "is not a valid duration: %v", err)
+		return fallback
+	}
+	if duration < 0 || (positive && duration == 0) {
+		l.fail(key, "must be positive, got %s", value)
+		return fallback
 	}
+	return duration
 }
 
-func loadEnv(key string) string {
-	value := os.Getenv(key)
+// url parses an absolute URL with one of schemes. Without a fallback the
+// variable is required.
+func (l *loader) url(key string, fallback string, schemes ...string) string {
+	value := l.string(key, fallback)
 	if value == "" {
-		logrus.Panic(fmt.Sprintf("Environment variable %s not set", key))
+		l.fail(key, "is not set")
+		return value
+	}
+	parsed, err := url.Parse(value)
+	if err != nil || parsed.Host == "" {
+		l.fail(key, "must be an absolute URL, got %q", value)
+		return value
+	}
+	for _, scheme := range schemes {
+		if parsed.Scheme == scheme {
+			return value
+		}
+	}
+	l.fail(key, "must use %s, got %q", strings.Join(schemes, " or "), parsed.Scheme)
+	return value
+}
+
+func (l *loader) snowflake(key string, required bool) string {
+	value := l.string(key, "")
+	if value == "" {
+		if required {
+			l.fail(key, "is not set")
+		}
+		return value
+	}
+	if !snowflakePattern.MatchString(value) {
+		l.fail(key, "must be a Discord ID, got %q", value)
+	}
+	return value
+}
+
+// snowflakes parses a comma separated list of Discord IDs.
+func (l *loader) snowflakes(key string, fallback []string) []string {
+	value := l.string(key, "")
+	if value == "" {
+		return fallback
+	}
+	result := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		if !snowflakePattern.MatchString(item) {
+			l.fail(key, "must be a list of Discord IDs, got %q", item)
+		}
+		result = append(result, item)
+	}
+	return result
+}
+
+// roleMappings parses a comma separated list of rds_role=discord_role_id pairs.
+func (l *loader) roleMappings(key string) map[string]string {
+	result := map[string]string{}
+	value := l.string(key, "")
+	if value == "" {
+		return result
+	}
+	for _, pair := range strings.Split(value, ",") {
+		name, mapped, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || name == "" || !snowflakePattern.MatchString(mapped) {
+			l.fail(key, "has an invalid entry %q, expected rds_role=discord_role_id", pair)
+			continue
+		}
+		result[name] = mapped
+	}
+	return result
+}
+
+// publicKey reads the hex encoded Ed25519 key Discord signs interactions with.
+func (l *loader) publicKey(key string) string {
+	value := l.required(key)
+	if value == "" {
+		return value
+	}
+	if decoded, err := hex.DecodeString(value); err != nil || len(decoded) != ed25519.PublicKeySize {
+		l.fail(key, "must be a hex encoded %d byte Ed25519 public key", ed25519.PublicKeySize)
+	}
+	return value
+}
+
+func (l *loader) privateKey(key string) string {
+	value := l.required(key)
+	if value == "" {
+		return value
+	}
+	if _, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(value)); err != nil {
+		l.fail(key, "must be a PEM encoded RSA private key: %v", err)
+	}
+	return value
+}
+
+func (l *loader) rdsPublicKey(key string) string {
+	value := l.string(key, "")
+	if value == "" {
+		return value
+	}
+	if _, err := jwt.ParseRSAPublicKeyFromPEM([]byte(value)); err != nil {
+		l.fail(key, "must be a PEM encoded RSA public key: %v", err)
 	}
 	return value
 }
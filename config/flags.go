@@ -0,0 +1,55 @@
+package config
+
+import (
+	"slices"
+	"strings"
+	"sync/atomic"
+)
+
+// CommandFlags are the commands turned off with DISABLED_COMMANDS. Copies share
+// their value, so a reload is seen by every copy.
+type CommandFlags struct {
+	disabled *atomic.Pointer[[]string]
+}
+
+// NewCommandFlags turns off the commands named disabled.
+func NewCommandFlags(disabled ...string) CommandFlags {
+	flags := CommandFlags{disabled: &atomic.Pointer[[]string]{}}
+	flags.set(disabled)
+	return flags
+}
+
+// parseCommandFlags reads command names separated by commas or whitespace, so
+// a flags file can list one command per line.
+func parseCommandFlags(value string) CommandFlags {
+	return NewCommandFlags(strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})...)
+}
+
+// Disabled reports whether the command named name is turned off.
+func (f CommandFlags) Disabled(name string) bool {
+	return slices.Contains(f.List(), name)
+}
+
+// List returns the names of the commands that are turned off, sorted.
+func (f CommandFlags) List() []string {
+	if f.disabled == nil {
+		return []string{}
+	}
+	return *f.disabled.Load()
+}
+
+func (f CommandFlags) set(disabled []string) {
+	if f.disabled == nil {
+		return
+	}
+	sorted := slices.Clone(disabled)
+	slices.Sort(sorted)
+	sorted = slices.Compact(sorted)
+	f.disabled.Store(&sorted)
+}
+
+func (f CommandFlags) String() string {
+	return strings.Join(f.List(), ",")
+}
@@ -0,0 +1,55 @@
+package config
+
+import "github.com/sirupsen/logrus"
+
+// Environments the service can run in, selected with ENV.
+const (
+	Development = "development"
+	Staging     = "staging"
+	Production  = "production"
+)
+
+// Where "commands register" puts commands, selected with REGISTRATION_SCOPE.
+const (
+	// RegisterGuilds registers every command, global ones included, in the
+	// guilds only, so changes show up instantly and never reach other guilds.
+	RegisterGuilds = "guilds"
+	// RegisterDeclared registers every command at its declared scope.
+	RegisterDeclared = "declared"
+	// RegisterPublic registers commands at their declared scope, but skips
+	// the dev scope.
+	RegisterPublic = "public"
+)
+
+// Profile holds the defaults ENV picks. Variables that are set still win.
+type Profile struct {
+	RDS_BASE_API_URL      string
+	MAIN_SITE_URL         string
+	VERIFICATION_SITE_URL string
+	LOG_LEVEL             logrus.Level
+	REGISTRATION_SCOPE    string
+}
+
+var Profiles = map[string]Profile{
+	Development: {
+		RDS_BASE_API_URL:      "http://localhost:3000",
+		MAIN_SITE_URL:         "http://localhost:4200",
+		VERIFICATION_SITE_URL: "http://localhost:3443",
+		LOG_LEVEL:             logrus.DebugLevel,
+		REGISTRATION_SCOPE:    RegisterGuilds,
+	},
+	Staging: {
+		RDS_BASE_API_URL:      "https://staging-api.realdevsquad.com",
+		MAIN_SITE_URL:         "https://staging-www.realdevsquad.com",
+		VERIFICATION_SITE_URL: "https://staging-my.realdevsquad.com",
+		LOG_LEVEL:             logrus.DebugLevel,
+		REGISTRATION_SCOPE:    RegisterDeclared,
+	},
+	Production: {
+		RDS_BASE_API_URL:      "https://api.realdevsquad.com",
+		MAIN_SITE_URL:         "https://www.realdevsquad.com",
+		VERIFICATION_SITE_URL: "https://my.realdevsquad.com",
+		LOG_LEVEL:             logrus.InfoLevel,
+		REGISTRATION_SCOPE:    RegisterPublic,
+	},
+}
@@ -0,0 +1,55 @@
+package config
+
+import (
+	"encoding/json"
+	"sync/atomic"
+)
+
+// Redacted is printed in place of a secret.
+const Redacted = "[REDACTED]"
+
+// Secret is a configuration value that must never be printed. Formatting,
+// logging or marshalling it yields Redacted, only Value returns the secret.
+// Copies of a Secret share its value, so a reload is seen by every copy.
+type Secret struct {
+	value *atomic.Pointer[string]
+}
+
+// NewSecret wraps value in a Secret.
+func NewSecret(value string) Secret {
+	secret := Secret{value: &atomic.Pointer[string]{}}
+	secret.value.Store(&value)
+	return secret
+}
+
+// Value returns the secret itself, or "" for the zero Secret.
+func (s Secret) Value() string {
+	if s.value == nil {
+		return ""
+	}
+	return *s.value.Load()
+}
+
+// set replaces the value seen by every copy of s.
+func (s Secret) set(value string) {
+	if s.value != nil {
+		s.value.Store(&value)
+	}
+}
+
+// String hides the secret, an unset secret prints as "" so it is still
+// possible to tell the two apart in logs.
+func (s Secret) String() string {
+	if s.Value() == "" {
+		return ""
+	}
+	return Redacted
+}
+
+func (s Secret) GoString() string {
+	return s.String()
+}
+
+func (s Secret) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.String())
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSecret(t *testing.T) {
+	t.Run("should never format the secret", func(t *testing.T) {
+		config := Defaults()
+		config.BOT_TOKEN = NewSecret("super-secret-token")
+		for _, format := range []string{"%v", "%+v", "%#v", "%s", "%q"} {
+			assert.NotContains(t, fmt.Sprintf(format, config), "super-secret-token", format)
+			assert.NotContains(t, fmt.Sprintf(format, config.BOT_TOKEN), "super-secret-token", format)
+		}
+		assert.Contains(t, fmt.Sprintf("%+v", config), "BOT_TOKEN:"+Redacted)
+	})
+
+	t.Run("should never marshal the secret", func(t *testing.T) {
+		config := Defaults()
+		config.BOT_TOKEN = NewSecret("super-secret-token")
+		data, err := json.Marshal(config)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(data), "super-secret-token")
+		assert.Contains(t, string(data), `"BOT_TOKEN":"[REDACTED]"`)
+	})
+
+	t.Run("should never log the secret", func(t *testing.T) {
+		var out bytes.Buffer
+		logger := logrus.New()
+		logger.SetOutput(&out)
+		logger.SetFormatter(&logrus.JSONFormatter{})
+		secret := NewSecret("super-secret-token")
+		logger.WithField("token", secret).Infof("token %v", secret)
+		assert.NotContains(t, out.String(), "super-secret-token")
+	})
+
+	t.Run("should print an unset secret as empty", func(t *testing.T) {
+		assert.Equal(t, "", NewSecret("").String())
+		assert.Equal(t, "", Secret{}.Value())
+	})
+
+	t.Run("should share a reloaded value between copies", func(t *testing.T) {
+		secret := NewSecret("old")
+		cp := secret
+		secret.set("new")
+		assert.Equal(t, "new", cp.Value())
+	})
+}
+
+func TestReload(t *testing.T) {
+	env := testEnv(t)
+	dir := t.TempDir()
+	tokenFile := filepath.Join(dir, "bot_token")
+	assert.NoError(t, os.WriteFile(tokenFile, []byte("old-token\n"), 0o600))
+	for key, value := range env {
+		if key != "BOT_TOKEN" {
+			t.Setenv(key, value)
+		}
+	}
+	t.Setenv("BOT_TOKEN_FILE", tokenFile)
+
+	original := AppConfig
+	defer func() { AppConfig = original }()
+	loaded, err := Load()
+	assert.NoError(t, err)
+	AppConfig = loaded
+	token := AppConfig.BOT_TOKEN
+	assert.Equal(t, "old-token", token.Value())
+
+	t.Run("should report that nothing changed", func(t *testing.T) {
+		changed, err := Reload()
+		assert.NoError(t, err)
+		assert.Empty(t, changed)
+	})
+
+	t.Run("should swap a rotated secret file", func(t *testing.T) {
+		assert.NoError(t, os.WriteFile(tokenFile, []byte("new-token\n"), 0o600))
+		changed, err := Reload()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"BOT_TOKEN"}, changed)
+		assert.Equal(t, "new-token", AppConfig.BOT_TOKEN.Value())
+		assert.Equal(t, "new-token", token.Value())
+	})
+
+	t.Run("should swap the disabled commands", func(t *testing.T) {
+		flagsFile := filepath.Join(dir, "disabled_commands")
+		assert.NoError(t, os.WriteFile(flagsFile, []byte("verify\n"), 0o600))
+		t.Setenv("DISABLED_COMMANDS_FILE", flagsFile)
+		flags := AppConfig.DISABLED_COMMANDS
+		changed, err := Reload()
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"DISABLED_COMMANDS"}, changed)
+		assert.True(t, flags.Disabled("verify"))
+	})
+
+	t.Run("should keep the queue URL until the service restarts", func(t *testing.T) {
+		queueURL := AppConfig.QUEUE_URL.Value()
+		t.Setenv("QUEUE_URL", "amqp://other-broker")
+		changed, err := Reload()
+		assert.NoError(t, err)
+		assert.NotContains(t, changed, "QUEUE_URL")
+		assert.Equal(t, queueURL, AppConfig.QUEUE_URL.Value())
+	})
+
+	t.Run("should keep the current secrets when the new configuration is invalid", func(t *testing.T) {
+		assert.NoError(t, os.WriteFile(tokenFile, []byte("rotated-token"), 0o600))
+		t.Setenv("BOT_PRIVATE_KEY", "<not-a-key>")
+		_, err := Reload()
+		assert.ErrorContains(t, err, "BOT_PRIVATE_KEY")
+		assert.Equal(t, "new-token", AppConfig.BOT_TOKEN.Value())
+	})
+}
@@ -24,12 +24,12 @@ func TestHomeHandler(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, w.Code)
 	})
 
-	t.Run("Should return 500 when fails to unmarshal request body", func(t *testing.T) {
+	t.Run("Should return 400 when fails to unmarshal request body", func(t *testing.T) {
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest("POST", "/", bytes.NewBuffer([]byte("malformed request")))
 		controllers.DiscordBaseHandler(w, r, nil)
 
-		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
 	})
 
 	t.Run("Should return 200 when request body is valid", func(t *testing.T) {
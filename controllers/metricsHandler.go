@@ -0,0 +1,14 @@
+package controllers
+
+import (
+	"expvar"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+// MetricsHandler serves the expvar counters. It is only routed behind
+// middleware.VerifyServiceToken, as the counters are not public.
+func MetricsHandler(response http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	expvar.Handler().ServeHTTP(response, request)
+}
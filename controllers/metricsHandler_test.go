@@ -0,0 +1,26 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Real-Dev-Squad/discord-service/controllers"
+	_ "github.com/Real-Dev-Squad/discord-service/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsHandler(t *testing.T) {
+	t.Run("should serve the expvar counters", func(t *testing.T) {
+		r, _ := http.NewRequest("GET", "/debug/vars", nil)
+		w := httptest.NewRecorder()
+
+		controllers.MetricsHandler(w, r, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		vars := map[string]json.RawMessage{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &vars))
+		assert.Contains(t, vars, "errors")
+	})
+}
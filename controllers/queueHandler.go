@@ -20,7 +20,15 @@ func QueueHandler(response http.ResponseWriter, request *http.Request, params ht
 	}
 	handler := handlers.MainHandler(body)
 	if handler != nil {
-		if err := utils.ExponentialBackoffRetry(config.AppConfig.MAX_RETRIES, handler); err != nil {
+		err := utils.ExponentialBackoffRetry(request.Context(), config.AppConfig.MAX_RETRIES, handler)
+		if request.Context().Err() != nil {
+			// The service is shutting down or the consumer went away: nack the
+			// job so that it is delivered again.
+			logrus.Warnf("Abandoned job before it finished: %v", err)
+			http.Error(response, "The service is shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		if err != nil {
 			logrus.Errorf("Failed to process command after %d attempts: %s", config.AppConfig.MAX_RETRIES, err)
 		}
 	}
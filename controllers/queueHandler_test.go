@@ -2,6 +2,7 @@ package controllers_test
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"net/http"
 	"net/http/httptest"
@@ -45,7 +46,7 @@ func TestQueueHandler(t *testing.T) {
 	t.Run("should fail if ExponentialBackoffRetry fails for listening command", func(t *testing.T) {
 		config.AppConfig.MAX_RETRIES = 1
 		originalFunc := utils.ExponentialBackoffRetry
-		utils.ExponentialBackoffRetry = func(maxRetries int, operation func() error) error {
+		utils.ExponentialBackoffRetry = func(ctx context.Context, maxRetries int, operation func() error) error {
 			return errors.New("error")
 		}
 		defer func() { utils.ExponentialBackoffRetry = originalFunc }()
@@ -61,4 +62,20 @@ func TestQueueHandler(t *testing.T) {
 		router.ServeHTTP(rr, req)
 		assert.Equal(t, http.StatusInternalServerError, rr.Code)
 	})
+
+	t.Run("should return 503 Service Unavailable so the job is nacked when abandoned during shutdown", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		originalFunc := utils.ExponentialBackoffRetry
+		utils.ExponentialBackoffRetry = func(ctx context.Context, maxRetries int, operation func() error) error {
+			cancel()
+			return ctx.Err()
+		}
+		defer func() { utils.ExponentialBackoffRetry = originalFunc }()
+		body := []byte(`{"CommandName": "listening"}`)
+		req, err := http.NewRequestWithContext(ctx, "POST", "/queue", bytes.NewBuffer(body))
+		assert.NoError(t, err)
+		rr := httptest.NewRecorder()
+		router.ServeHTTP(rr, req)
+		assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+	})
 }
@@ -0,0 +1,12 @@
+package controllers
+
+import (
+	"net/http"
+
+	service "github.com/Real-Dev-Squad/discord-service/service"
+	"github.com/julienschmidt/httprouter"
+)
+
+func RoleSyncHandler(response http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	service.RoleSyncService(response, request)
+}
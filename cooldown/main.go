@@ -0,0 +1,107 @@
+package cooldown
+
+import (
+	"sync"
+	"time"
+)
+
+// Store tracks recent uses of a key. Implementations must be safe for
+// concurrent use so that a shared store can back several instances.
+type Store interface {
+	// Take records a use of key if fewer than burst uses happened within the
+	// last window. When the burst is exhausted it records nothing and returns
+	// how long the caller has to wait.
+	Take(key string, window time.Duration, burst int, now time.Time) (time.Duration, bool)
+	// Refund forgets the latest use of key, for a use that did not go through.
+	Refund(key string)
+}
+
+// sweepInterval is how often MemoryStore forgets keys whose uses all expired.
+const sweepInterval = time.Minute
+
+// MemoryStore keeps a sliding window of use timestamps per key in process memory.
+type MemoryStore struct {
+	mu    sync.Mutex
+	uses  map[string]*uses
+	swept time.Time
+}
+
+type uses struct {
+	times  []time.Time
+	window time.Duration
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{uses: make(map[string]*uses)}
+}
+
+func (m *MemoryStore) Take(key string, window time.Duration, burst int, now time.Time) (time.Duration, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sweep(now)
+
+	entry, ok := m.uses[key]
+	if !ok {
+		entry = &uses{}
+		m.uses[key] = entry
+	}
+	entry.window = window
+	recent := entry.times[:0]
+	for _, used := range entry.times {
+		if now.Sub(used) < window {
+			recent = append(recent, used)
+		}
+	}
+	if len(recent) >= burst {
+		entry.times = recent
+		return recent[0].Add(window).Sub(now), false
+	}
+	entry.times = append(recent, now)
+	return 0, true
+}
+
+func (m *MemoryStore) Refund(key string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.uses[key]
+	if !ok {
+		return
+	}
+	if len(entry.times) > 0 {
+		entry.times = entry.times[:len(entry.times)-1]
+	}
+	if len(entry.times) == 0 {
+		delete(m.uses, key)
+	}
+}
+
+// sweep forgets the keys whose latest use is older than their window, so users
+// who stopped invoking a command do not stay in memory.
+func (m *MemoryStore) sweep(now time.Time) {
+	if now.Sub(m.swept) < sweepInterval {
+		return
+	}
+	m.swept = now
+	for key, entry := range m.uses {
+		if len(entry.times) == 0 || now.Sub(entry.times[len(entry.times)-1]) >= entry.window {
+			delete(m.uses, key)
+		}
+	}
+}
+
+var DefaultStore Store = NewMemoryStore()
+
+var Now = time.Now
+
+// Check records a use of command by user against DefaultStore and returns the
+// remaining wait when the user is on cooldown.
+func Check(command string, userID string, window time.Duration, burst int) (time.Duration, bool) {
+	return DefaultStore.Take(command+":"+userID, window, burst, Now())
+}
+
+// Refund gives back the use recorded by Check when the command failed before
+// doing anything, so the user can retry right away.
+func Refund(command string, userID string) {
+	DefaultStore.Refund(command + ":" + userID)
+}
@@ -0,0 +1,91 @@
+package cooldown
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+
+	t.Run("should allow uses up to the burst size", func(t *testing.T) {
+		store := NewMemoryStore()
+		for i := 0; i < 3; i++ {
+			_, ok := store.Take("key", time.Minute, 3, now)
+			assert.True(t, ok)
+		}
+		remaining, ok := store.Take("key", time.Minute, 3, now.Add(10*time.Second))
+		assert.False(t, ok)
+		assert.Equal(t, 50*time.Second, remaining)
+	})
+
+	t.Run("should allow uses again once the window has passed", func(t *testing.T) {
+		store := NewMemoryStore()
+		_, ok := store.Take("key", time.Minute, 1, now)
+		assert.True(t, ok)
+		_, ok = store.Take("key", time.Minute, 1, now.Add(59*time.Second))
+		assert.False(t, ok)
+		_, ok = store.Take("key", time.Minute, 1, now.Add(time.Minute))
+		assert.True(t, ok)
+	})
+
+	t.Run("should not record rejected uses", func(t *testing.T) {
+		store := NewMemoryStore()
+		store.Take("key", time.Minute, 1, now)
+		store.Take("key", time.Minute, 1, now.Add(30*time.Second))
+		_, ok := store.Take("key", time.Minute, 1, now.Add(time.Minute))
+		assert.True(t, ok)
+	})
+
+	t.Run("should track keys independently", func(t *testing.T) {
+		store := NewMemoryStore()
+		_, ok := store.Take("a", time.Minute, 1, now)
+		assert.True(t, ok)
+		_, ok = store.Take("b", time.Minute, 1, now)
+		assert.True(t, ok)
+	})
+
+	t.Run("should allow a use again once it is refunded", func(t *testing.T) {
+		store := NewMemoryStore()
+		store.Take("key", time.Minute, 1, now)
+		store.Refund("key")
+		_, ok := store.Take("key", time.Minute, 1, now.Add(time.Second))
+		assert.True(t, ok)
+	})
+
+	t.Run("should forget keys once their uses have expired", func(t *testing.T) {
+		store := NewMemoryStore()
+		store.Take("a", time.Minute, 1, now)
+		store.Take("b", time.Hour, 1, now)
+		store.Take("c", time.Minute, 1, now.Add(2*sweepInterval))
+		assert.NotContains(t, store.uses, "a")
+		assert.Contains(t, store.uses, "b")
+		assert.Contains(t, store.uses, "c")
+	})
+}
+
+func TestCheck(t *testing.T) {
+	originalStore := DefaultStore
+	defer func() { DefaultStore = originalStore }()
+	DefaultStore = NewMemoryStore()
+
+	t.Run("should key cooldowns by command and user", func(t *testing.T) {
+		_, ok := Check("verify", "1", time.Minute, 1)
+		assert.True(t, ok)
+		_, ok = Check("verify", "1", time.Minute, 1)
+		assert.False(t, ok)
+		_, ok = Check("verify", "2", time.Minute, 1)
+		assert.True(t, ok)
+		_, ok = Check("listening", "1", time.Minute, 1)
+		assert.True(t, ok)
+	})
+
+	t.Run("should refund a use by command and user", func(t *testing.T) {
+		Check("hello", "1", time.Minute, 1)
+		Refund("hello", "1")
+		_, ok := Check("hello", "1", time.Minute, 1)
+		assert.True(t, ok)
+	})
+}
@@ -0,0 +1,256 @@
+package discord
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Real-Dev-Squad/discord-service/metrics"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	headerBucket     = "X-RateLimit-Bucket"
+	headerRemaining  = "X-RateLimit-Remaining"
+	headerResetAfter = "X-RateLimit-Reset-After"
+	headerGlobal     = "X-RateLimit-Global"
+	headerRetryAfter = "Retry-After"
+)
+
+const DefaultMaxRateLimitRetries = 3
+
+// sweepInterval is how often buckets whose limit has reset are forgotten.
+const sweepInterval = time.Minute
+
+// majorParameters are the path parameters Discord keeps separate limits for.
+// Every other ID in a path shares the limit of its route.
+var majorParameters = map[string]bool{"channels": true, "guilds": true, "webhooks": true}
+
+// bucket is the last known rate limit state of a Discord bucket.
+type bucket struct {
+	id        string
+	remaining int
+	reset     time.Time
+}
+
+// RateLimitTransport is an http.RoundTripper for the Discord REST API that
+// holds requests back while their route or the whole bot is rate limited and
+// retries requests rejected with 429 once Retry-After has passed, instead of
+// surfacing the limit as an error.
+type RateLimitTransport struct {
+	Base       http.RoundTripper
+	MaxRetries int
+
+	mu          sync.Mutex
+	routes      map[string]string
+	buckets     map[string]*bucket
+	globalReset time.Time
+	nextSweep   time.Time
+}
+
+func NewRateLimitTransport(base http.RoundTripper) *RateLimitTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &RateLimitTransport{
+		Base:       base,
+		MaxRetries: DefaultMaxRateLimitRetries,
+		routes:     make(map[string]string),
+		buckets:    make(map[string]*bucket),
+	}
+}
+
+var Now = time.Now
+
+// route returns the route template of the request, with every ID replaced by a
+// placeholder, and the values of its major parameters. Requests with the same
+// route share a bucket, e.g. every "PATCH /guilds/{id}/members/{id}" of a guild.
+func route(request *http.Request) (string, string) {
+	segments := strings.Split(strings.Trim(request.URL.Path, "/"), "/")
+	major := []string{}
+	for i, segment := range segments {
+		if !isID(segment) {
+			continue
+		}
+		parent := ""
+		if i > 0 {
+			parent = segments[i-1]
+		}
+		if majorParameters[parent] {
+			major = append(major, segment)
+		}
+		// Webhook and interaction tokens follow their ID. A webhook token is
+		// part of the webhook's major parameter.
+		if (parent == "webhooks" || parent == "interactions") && i+1 < len(segments) {
+			if parent == "webhooks" {
+				major = append(major, segments[i+1])
+			}
+			segments[i+1] = "{token}"
+		}
+		segments[i] = "{id}"
+	}
+	return request.Method + " /" + strings.Join(segments, "/"), strings.Join(major, "/")
+}
+
+func isID(segment string) bool {
+	if segment == "" {
+		return false
+	}
+	for _, r := range segment {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// bucketKey is where the state of a route is kept: under the bucket Discord
+// reported for the route once known, else under the route itself. Limits are
+// kept per major parameter either way.
+func (t *RateLimitTransport) bucketKey(routeKey string, major string) string {
+	if id, ok := t.routes[routeKey]; ok {
+		return id + " " + major
+	}
+	return routeKey + " " + major
+}
+
+func (t *RateLimitTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	key, major := route(request)
+	for attempt := 0; ; attempt++ {
+		if err := t.waitForCapacity(request.Context(), key, major); err != nil {
+			return nil, err
+		}
+
+		current := request
+		if attempt > 0 {
+			current = request.Clone(request.Context())
+			if request.GetBody != nil {
+				body, err := request.GetBody()
+				if err != nil {
+					return nil, err
+				}
+				current.Body = body
+			}
+		}
+
+		response, err := t.Base.RoundTrip(current)
+		if err != nil {
+			return nil, err
+		}
+		t.update(key, major, response)
+
+		if response.StatusCode != http.StatusTooManyRequests || attempt >= t.MaxRetries {
+			return response, nil
+		}
+		if request.GetBody == nil && request.Body != nil && request.Body != http.NoBody {
+			// The body has been consumed and cannot be sent again.
+			return response, nil
+		}
+		metrics.DiscordRateLimit.Add(metrics.RateLimitRetries, 1)
+		response.Body.Close()
+	}
+}
+
+// waitForCapacity blocks until neither the global limit nor the route's bucket
+// is exhausted, or until the request is cancelled.
+func (t *RateLimitTransport) waitForCapacity(ctx context.Context, key string, major string) error {
+	for {
+		wait, scope := t.reserve(key, major)
+		if wait <= 0 {
+			return nil
+		}
+		metrics.DiscordRateLimit.Add(metrics.RateLimitWaits, 1)
+		metrics.DiscordRateLimit.Add(metrics.RateLimitWaitMilliseconds, wait.Milliseconds())
+		logrus.WithFields(logrus.Fields{"route": key, "bucket": scope}).Warnf("Discord rate limit reached, waiting %s", wait)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// reserve takes one request from the route's bucket, or returns how long the
+// caller has to wait and which limit it is waiting on.
+func (t *RateLimitTransport) reserve(key string, major string) (time.Duration, string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := Now()
+	if wait := t.globalReset.Sub(now); wait > 0 {
+		return wait, "global"
+	}
+	b, ok := t.buckets[t.bucketKey(key, major)]
+	if !ok || !now.Before(b.reset) {
+		return 0, ""
+	}
+	if b.remaining <= 0 {
+		return b.reset.Sub(now), b.id
+	}
+	b.remaining--
+	return 0, ""
+}
+
+// update records the limits Discord reported for the route, and which bucket
+// the route belongs to.
+func (t *RateLimitTransport) update(key string, major string, response *http.Response) {
+	header := response.Header
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := Now()
+	t.sweep(now)
+	if id := header.Get(headerBucket); id != "" {
+		t.routes[key] = id
+	}
+
+	if response.StatusCode == http.StatusTooManyRequests {
+		retryAfter := parseSeconds(header.Get(headerRetryAfter))
+		if retryAfter <= 0 {
+			retryAfter = parseSeconds(header.Get(headerResetAfter))
+		}
+		if header.Get(headerGlobal) == "true" {
+			metrics.DiscordRateLimit.Add(metrics.RateLimitGlobal, 1)
+			t.globalReset = now.Add(retryAfter)
+			return
+		}
+		t.buckets[t.bucketKey(key, major)] = &bucket{id: header.Get(headerBucket), remaining: 0, reset: now.Add(retryAfter)}
+		return
+	}
+
+	remaining, err := strconv.Atoi(header.Get(headerRemaining))
+	if err != nil {
+		return
+	}
+	t.buckets[t.bucketKey(key, major)] = &bucket{
+		id:        header.Get(headerBucket),
+		remaining: remaining,
+		reset:     now.Add(parseSeconds(header.Get(headerResetAfter))),
+	}
+}
+
+// sweep forgets buckets whose limit has reset, they no longer hold anything
+// back. Route to bucket mappings are kept, there is one per route template.
+func (t *RateLimitTransport) sweep(now time.Time) {
+	if now.Before(t.nextSweep) {
+		return
+	}
+	t.nextSweep = now.Add(sweepInterval)
+	for key, b := range t.buckets {
+		if !now.Before(b.reset) {
+			delete(t.buckets, key)
+		}
+	}
+}
+
+func parseSeconds(value string) time.Duration {
+	seconds, err := strconv.ParseFloat(value, 64)
+	if err != nil || seconds < 0 {
+		return 0
+	}
+	return time.Duration(seconds * float64(time.Second))
+}
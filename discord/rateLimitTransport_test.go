@@ -0,0 +1,274 @@
+package discord
+
+import (
+	"context"
+	"expvar"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Real-Dev-Squad/discord-service/metrics"
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+)
+
+func metricValue(name string) int64 {
+	if v, ok := metrics.DiscordRateLimit.Get(name).(*expvar.Int); ok {
+		return v.Value()
+	}
+	return 0
+}
+
+// fakeDiscord serves the Discord API from a list of canned responses, one per request.
+type fakeDiscord struct {
+	responses []func(w http.ResponseWriter)
+	requests  atomic.Int32
+	bodies    []string
+}
+
+func (f *fakeDiscord) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	n := int(f.requests.Add(1)) - 1
+	body, _ := io.ReadAll(r.Body)
+	f.bodies = append(f.bodies, string(body))
+	if n >= len(f.responses) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	f.responses[n](w)
+}
+
+func rateLimited(retryAfter string, global bool) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		w.Header().Set("Retry-After", retryAfter)
+		w.Header().Set("X-RateLimit-Bucket", "bucket-1")
+		if global {
+			w.Header().Set("X-RateLimit-Global", "true")
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"message": "You are being rate limited.", "retry_after": ` + retryAfter + `, "global": false}`))
+	}
+}
+
+func ok(remaining string, resetAfter string) func(w http.ResponseWriter) {
+	return func(w http.ResponseWriter) {
+		w.Header().Set("X-RateLimit-Bucket", "bucket-1")
+		w.Header().Set("X-RateLimit-Remaining", remaining)
+		w.Header().Set("X-RateLimit-Reset-After", resetAfter)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func newClient(server *httptest.Server) (*http.Client, *RateLimitTransport) {
+	transport := NewRateLimitTransport(server.Client().Transport)
+	return &http.Client{Transport: transport}, transport
+}
+
+func TestRateLimitTransport(t *testing.T) {
+	t.Run("should pass responses through when not rate limited", func(t *testing.T) {
+		fake := &fakeDiscord{responses: []func(w http.ResponseWriter){ok("4", "1")}}
+		server := httptest.NewServer(fake)
+		defer server.Close()
+		client, _ := newClient(server)
+
+		response, err := client.Get(server.URL + "/guilds/1/members/2")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, response.StatusCode)
+		assert.Equal(t, int32(1), fake.requests.Load())
+	})
+
+	t.Run("should retry after Retry-After when rate limited", func(t *testing.T) {
+		retries := metricValue(metrics.RateLimitRetries)
+		fake := &fakeDiscord{responses: []func(w http.ResponseWriter){rateLimited("0.05", false), ok("4", "1")}}
+		server := httptest.NewServer(fake)
+		defer server.Close()
+		client, _ := newClient(server)
+
+		start := time.Now()
+		request, _ := http.NewRequest("PATCH", server.URL+"/guilds/1/members/2", strings.NewReader(`{"nick":"new"}`))
+		response, err := client.Do(request)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusNoContent, response.StatusCode)
+		assert.Equal(t, int32(2), fake.requests.Load())
+		assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+		assert.Equal(t, []string{`{"nick":"new"}`, `{"nick":"new"}`}, fake.bodies)
+		assert.Equal(t, retries+1, metricValue(metrics.RateLimitRetries))
+	})
+
+	t.Run("should return the 429 response once retries are exhausted", func(t *testing.T) {
+		fake := &fakeDiscord{responses: []func(w http.ResponseWriter){
+			rateLimited("0.01", false), rateLimited("0.01", false), rateLimited("0.01", false),
+		}}
+		server := httptest.NewServer(fake)
+		defer server.Close()
+		client, transport := newClient(server)
+		transport.MaxRetries = 2
+
+		response, err := client.Get(server.URL + "/guilds/1/members/2")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+		assert.Equal(t, int32(3), fake.requests.Load())
+	})
+
+	t.Run("should hold requests back while the bucket is exhausted", func(t *testing.T) {
+		waits := metricValue(metrics.RateLimitWaits)
+		fake := &fakeDiscord{responses: []func(w http.ResponseWriter){ok("0", "0.05"), ok("4", "1")}}
+		server := httptest.NewServer(fake)
+		defer server.Close()
+		client, _ := newClient(server)
+
+		_, err := client.Get(server.URL + "/guilds/1/members/2")
+		assert.NoError(t, err)
+		start := time.Now()
+		_, err = client.Get(server.URL + "/guilds/1/members/2")
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+		assert.Equal(t, waits+1, metricValue(metrics.RateLimitWaits))
+	})
+
+	t.Run("should not hold back requests to other routes", func(t *testing.T) {
+		fake := &fakeDiscord{responses: []func(w http.ResponseWriter){ok("0", "10"), ok("4", "1")}}
+		server := httptest.NewServer(fake)
+		defer server.Close()
+		client, _ := newClient(server)
+
+		_, err := client.Get(server.URL + "/guilds/1/members/2")
+		assert.NoError(t, err)
+		start := time.Now()
+		_, err = client.Get(server.URL + "/channels/3/messages")
+		assert.NoError(t, err)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("should hold back every route during a global rate limit", func(t *testing.T) {
+		global := metricValue(metrics.RateLimitGlobal)
+		fake := &fakeDiscord{responses: []func(w http.ResponseWriter){rateLimited("0.05", true), ok("4", "1"), ok("4", "1")}}
+		server := httptest.NewServer(fake)
+		defer server.Close()
+		client, transport := newClient(server)
+		transport.MaxRetries = 0
+
+		response, err := client.Get(server.URL + "/guilds/1/members/2")
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusTooManyRequests, response.StatusCode)
+		assert.Equal(t, global+1, metricValue(metrics.RateLimitGlobal))
+
+		start := time.Now()
+		_, err = client.Get(server.URL + "/channels/3/messages")
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+	})
+
+	t.Run("should stop waiting when the request is cancelled", func(t *testing.T) {
+		fake := &fakeDiscord{responses: []func(w http.ResponseWriter){ok("0", "10")}}
+		server := httptest.NewServer(fake)
+		defer server.Close()
+		client, _ := newClient(server)
+
+		_, err := client.Get(server.URL + "/guilds/1/members/2")
+		assert.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		request, _ := http.NewRequestWithContext(ctx, "GET", server.URL+"/guilds/1/members/2", nil)
+		_, err = client.Do(request)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Equal(t, int32(1), fake.requests.Load())
+	})
+}
+
+func TestRoute(t *testing.T) {
+	tests := []struct {
+		method string
+		path   string
+		route  string
+		major  string
+	}{
+		{"PATCH", "/api/v9/guilds/111/members/222", "PATCH /api/v9/guilds/{id}/members/{id}", "111"},
+		{"PUT", "/api/v9/guilds/111/members/222/roles/333", "PUT /api/v9/guilds/{id}/members/{id}/roles/{id}", "111"},
+		{"GET", "/api/v9/channels/444/messages", "GET /api/v9/channels/{id}/messages", "444"},
+		{"PATCH", "/api/v9/webhooks/555/token-abc/messages/@original", "PATCH /api/v9/webhooks/{id}/{token}/messages/@original", "555/token-abc"},
+		{"POST", "/api/v9/interactions/666/token-def/callback", "POST /api/v9/interactions/{id}/{token}/callback", ""},
+	}
+	for _, test := range tests {
+		t.Run("should template "+test.path, func(t *testing.T) {
+			request, _ := http.NewRequest(test.method, "https://discord.com"+test.path, nil)
+			route, major := route(request)
+			assert.Equal(t, test.route, route)
+			assert.Equal(t, test.major, major)
+		})
+	}
+}
+
+func TestRateLimitTransportBuckets(t *testing.T) {
+	t.Run("should hold back requests for other members once the route's bucket is exhausted", func(t *testing.T) {
+		fake := &fakeDiscord{responses: []func(w http.ResponseWriter){ok("0", "0.05"), ok("4", "1")}}
+		server := httptest.NewServer(fake)
+		defer server.Close()
+		client, _ := newClient(server)
+
+		_, err := client.Get(server.URL + "/guilds/1/members/2")
+		assert.NoError(t, err)
+		start := time.Now()
+		_, err = client.Get(server.URL + "/guilds/1/members/3")
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+	})
+
+	t.Run("should share the bucket Discord reports between routes", func(t *testing.T) {
+		fake := &fakeDiscord{responses: []func(w http.ResponseWriter){ok("4", "1"), ok("4", "1"), ok("0", "0.05"), ok("4", "1")}}
+		server := httptest.NewServer(fake)
+		defer server.Close()
+		client, _ := newClient(server)
+
+		client.Get(server.URL + "/guilds/1/members/2")
+		client.Get(server.URL + "/guilds/1/members/2/roles/9")
+		client.Get(server.URL + "/guilds/1/members/2")
+		start := time.Now()
+		_, err := client.Get(server.URL + "/guilds/1/members/2/roles/9")
+		assert.NoError(t, err)
+		assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+	})
+
+	t.Run("should forget buckets once their limit has reset", func(t *testing.T) {
+		originalNow := Now
+		defer func() { Now = originalNow }()
+		now := time.Now()
+		Now = func() time.Time { return now }
+		fake := &fakeDiscord{responses: []func(w http.ResponseWriter){ok("4", "1"), ok("4", "1")}}
+		server := httptest.NewServer(fake)
+		defer server.Close()
+		client, transport := newClient(server)
+
+		client.Get(server.URL + "/guilds/1/members/2")
+		assert.Len(t, transport.buckets, 1)
+		now = now.Add(2 * sweepInterval)
+		client.Get(server.URL + "/channels/3/messages")
+		assert.Len(t, transport.buckets, 1)
+		assert.Contains(t, transport.buckets, "bucket-1 3")
+	})
+}
+
+func TestRateLimitTransportWithDiscordgo(t *testing.T) {
+	t.Run("should queue a nickname change behind a rate limit instead of failing", func(t *testing.T) {
+		fake := &fakeDiscord{responses: []func(w http.ResponseWriter){rateLimited("0.05", false), ok("4", "1")}}
+		server := httptest.NewServer(fake)
+		defer server.Close()
+		originalEndpoint := discordgo.EndpointGuilds
+		discordgo.EndpointGuilds = server.URL + "/guilds/"
+		defer func() { discordgo.EndpointGuilds = originalEndpoint }()
+
+		session, err := discordgo.New("Bot token")
+		assert.NoError(t, err)
+		session.Client.Transport = NewRateLimitTransport(server.Client().Transport)
+		session.ShouldRetryOnRateLimit = false
+
+		err = session.GuildMemberNickname("1", "2", "new")
+		assert.NoError(t, err)
+		assert.Equal(t, int32(2), fake.requests.Load())
+	})
+}
@@ -1,7 +1,43 @@
 package dtos
 
+import (
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+)
+
 type CommandNameTypes struct {
-	Hello     string
-	Listening string
-	Verify    string
+	Hello             string
+	Listening         string
+	Verify            string
+	MemberSync        string
+	RoleSync          string
+	VerifyComplete    string
+	NicknameReconcile string
+	NicknameRepair    string
+}
+
+// CommandScope is where a command is registered.
+type CommandScope string
+
+const (
+	ScopeGuilds   CommandScope = "guilds" // every guild in COMMAND_GUILD_IDS, the default
+	ScopeGlobal   CommandScope = "global" // every guild and direct messages
+	ScopeDevGuild CommandScope = "dev"    // DEV_GUILD_ID only
+)
+
+// CommandDefinition pairs a Discord application command with where it is
+// registered and the contexts the dispatcher is allowed to run it in.
+type CommandDefinition struct {
+	*discordgo.ApplicationCommand
+	Scope            CommandScope
+	AllowDM          bool // can be invoked from a direct message
+	AllowOtherGuilds bool // can be invoked from guilds it is not registered in
+	Cooldown         *Cooldown
+}
+
+// Cooldown limits a user to Burst invocations of a command per Window.
+type Cooldown struct {
+	Window time.Duration
+	Burst  int
 }
@@ -1,6 +1,9 @@
 package dtos
 
-import "github.com/bwmarrin/discordgo"
+import (
+	"github.com/Real-Dev-Squad/discord-service/i18n"
+	"github.com/bwmarrin/discordgo"
+)
 
 type Data struct {
 	discordgo.ApplicationCommandInteractionData
@@ -15,6 +18,30 @@ type DiscordMessage struct {
 	Type           discordgo.InteractionType `json:"type"`
 	Channel        *discordgo.Channel        `json:"channel"`
 	ChannelId      string                    `json:"channel_id"`
+	GuildId        string                    `json:"guild_id"`
 	Member         *discordgo.Member         `json:"member"`
+	User           *discordgo.User           `json:"user"`
 	Data           *Data                     `json:"data"`
+	Locale         discordgo.Locale          `json:"locale"`
+	GuildLocale    discordgo.Locale          `json:"guild_locale"`
+}
+
+// InvokingUser returns the user who triggered the interaction. Discord sends
+// it under member for guild interactions and under user for direct messages.
+func (d *DiscordMessage) InvokingUser() *discordgo.User {
+	if d.Member != nil && d.Member.User != nil {
+		return d.Member.User
+	}
+	return d.User
+}
+
+// IsDM reports whether the interaction was sent from a direct message.
+func (d *DiscordMessage) IsDM() bool {
+	return d.GuildId == ""
+}
+
+// PreferredLocale is the locale of the invoking user, else of the guild the
+// interaction was sent from, else i18n.DefaultLocale.
+func (d *DiscordMessage) PreferredLocale() discordgo.Locale {
+	return i18n.Pick(d.Locale, d.GuildLocale)
 }
@@ -0,0 +1,14 @@
+package dtos
+
+import "github.com/bwmarrin/discordgo"
+
+// NewEphemeralResponse builds a message response that only the invoking user can see.
+func NewEphemeralResponse(content string) *discordgo.InteractionResponse {
+	return &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Content: content,
+			Flags:   discordgo.MessageFlagsEphemeral,
+		},
+	}
+}
@@ -0,0 +1,17 @@
+package dtos
+
+import (
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewEphemeralResponse(t *testing.T) {
+	t.Run("should build an ephemeral channel message response", func(t *testing.T) {
+		response := NewEphemeralResponse("hello")
+		assert.Equal(t, discordgo.InteractionResponseChannelMessageWithSource, response.Type)
+		assert.Equal(t, "hello", response.Data.Content)
+		assert.Equal(t, discordgo.MessageFlagsEphemeral, response.Data.Flags)
+	})
+}
@@ -8,6 +8,9 @@ import (
 
 type DataPacket struct {
 	UserID      string `json:"userId"`
+	// GuildID is the guild a slash command was invoked from. Jobs without one
+	// act on GUILD_ID.
+	GuildID     string `json:"guildId,omitempty"`
 	CommandName string `json:"commandName"`
 	MetaData    map[string]string `json:"metaData"`
 }
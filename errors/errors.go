@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/Real-Dev-Squad/discord-service/dtos"
+	"github.com/Real-Dev-Squad/discord-service/i18n"
+	"github.com/bwmarrin/discordgo"
 	"github.com/sirupsen/logrus"
 )
 
@@ -59,6 +62,10 @@ func NewInternalServerError(message string, err error) *AppError {
 	return New(http.StatusInternalServerError, message, err)
 }
 
+func NewTooManyRequests(message string, err error) *AppError {
+	return New(http.StatusTooManyRequests, message, err)
+}
+
 // IsAppError checks if an error is an AppError
 func IsAppError(err error) (*AppError, bool) {
 	var appErr *AppError
@@ -97,4 +104,59 @@ func HandleError(w http.ResponseWriter, err error) {
 	if err:= json.NewEncoder(w).Encode(response); err != nil {
 		logrus.Errorf("failed to write error response %v", err)
 	}
-}
\ No newline at end of file
+}
+
+// DefaultInteractionMessage is shown to the user when an error carries no
+// message that is safe to display.
+var DefaultInteractionMessage = i18n.T(i18n.DefaultLocale, i18n.ErrorDefault)
+
+// interactionMessages holds the fallback reply for client errors without a message.
+var interactionMessages = map[int]i18n.Key{
+	http.StatusBadRequest:      i18n.ErrorBadRequest,
+	http.StatusUnauthorized:    i18n.ErrorNotAllowed,
+	http.StatusForbidden:       i18n.ErrorNotAllowed,
+	http.StatusTooManyRequests: i18n.ErrorTooManyRequests,
+}
+
+// InteractionMessage picks the user-facing message for an error in
+// i18n.DefaultLocale.
+func InteractionMessage(err error) string {
+	return InteractionMessageIn(err, i18n.DefaultLocale)
+}
+
+// InteractionMessageIn picks the user-facing message for an error. Client
+// errors show their own message, which the caller has already localized, and
+// server errors never leak details to the user.
+func InteractionMessageIn(err error, locale discordgo.Locale) string {
+	appErr, ok := IsAppError(err)
+	if !ok || appErr.Code >= http.StatusInternalServerError {
+		return i18n.T(locale, i18n.ErrorDefault)
+	}
+	if appErr.Message != "" {
+		return appErr.Message
+	}
+	if key, ok := interactionMessages[appErr.Code]; ok {
+		return i18n.T(locale, key)
+	}
+	return i18n.T(locale, i18n.ErrorDefault)
+}
+
+// HandleInteractionError replies to an application command interaction with an
+// ephemeral message describing the error in i18n.DefaultLocale.
+func HandleInteractionError(w http.ResponseWriter, err error) {
+	HandleInteractionErrorIn(w, err, i18n.DefaultLocale)
+}
+
+// HandleInteractionErrorIn replies to an application command interaction with an
+// ephemeral message describing the error. Discord cannot show an HTTP error body,
+// so the response is always a valid InteractionResponse with a 200 status.
+func HandleInteractionErrorIn(w http.ResponseWriter, err error, locale discordgo.Locale) {
+	logrus.Errorf("failed to handle interaction: %v", err)
+
+	response := dtos.NewEphemeralResponse(InteractionMessageIn(err, locale))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logrus.Errorf("failed to write interaction error response %v", err)
+	}
+}
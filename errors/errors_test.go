@@ -7,6 +7,8 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/Real-Dev-Squad/discord-service/i18n"
+	"github.com/bwmarrin/discordgo"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -163,3 +165,70 @@ func TestHandleError(t *testing.T) {
 		assert.Equal(t, string(bytes) + "\n", rr.Body.String())
 	})
 }
+
+func TestNewTooManyRequests(t *testing.T) {
+	t.Run("should create too many requests error", func(t *testing.T) {
+		appErr := NewTooManyRequests("Slow down", nil)
+		assert.Equal(t, http.StatusTooManyRequests, appErr.Code)
+		assert.Equal(t, "Slow down", appErr.Message)
+	})
+}
+
+func TestInteractionMessage(t *testing.T) {
+	t.Run("should use the message of a client error", func(t *testing.T) {
+		assert.Equal(t, "Invalid option", InteractionMessage(NewBadRequest("Invalid option", nil)))
+	})
+
+	t.Run("should fall back to the message for the status code", func(t *testing.T) {
+		assert.Equal(t, i18n.T(i18n.DefaultLocale, interactionMessages[http.StatusForbidden]), InteractionMessage(NewForbidden("", nil)))
+	})
+
+	t.Run("should use the locale of the interaction for fallback messages", func(t *testing.T) {
+		assert.Equal(t, i18n.T(discordgo.Hindi, i18n.ErrorNotAllowed), InteractionMessageIn(NewForbidden("", nil), discordgo.Hindi))
+		assert.Equal(t, i18n.T(discordgo.Hindi, i18n.ErrorDefault), InteractionMessageIn(errors.New("queue error"), discordgo.Hindi))
+		assert.NotEqual(t, DefaultInteractionMessage, InteractionMessageIn(errors.New("queue error"), discordgo.Hindi))
+	})
+
+	t.Run("should hide the message of a server error", func(t *testing.T) {
+		err := NewInternalServerError("database connection refused", nil)
+		assert.Equal(t, DefaultInteractionMessage, InteractionMessage(err))
+	})
+
+	t.Run("should hide regular errors", func(t *testing.T) {
+		assert.Equal(t, DefaultInteractionMessage, InteractionMessage(errors.New("queue error")))
+	})
+}
+
+func TestHandleInteractionError(t *testing.T) {
+	t.Run("should reply with an ephemeral interaction response", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		HandleInteractionError(rr, NewBadRequest("Invalid option", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+		response := discordgo.InteractionResponse{}
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, discordgo.InteractionResponseChannelMessageWithSource, response.Type)
+		assert.Equal(t, "Invalid option", response.Data.Content)
+		assert.Equal(t, discordgo.MessageFlagsEphemeral, response.Data.Flags)
+	})
+
+	t.Run("should reply with the default message for regular errors", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		HandleInteractionError(rr, errors.New("queue error"))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		response := discordgo.InteractionResponse{}
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, DefaultInteractionMessage, response.Data.Content)
+	})
+
+	t.Run("should reply in the given locale", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		HandleInteractionErrorIn(rr, errors.New("queue error"), discordgo.Hindi)
+
+		response := discordgo.InteractionResponse{}
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, i18n.T(discordgo.Hindi, i18n.ErrorDefault), response.Data.Content)
+	})
+}
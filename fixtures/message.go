@@ -15,6 +15,7 @@ var HelloCommand = &dtos.DiscordMessage{
 		Name: "general",
 	},
 	ChannelId: "987654321098765432",
+	GuildId:   "876543210987654321",
 	Member: &discordgo.Member{
 		User: &discordgo.User{
 			ID:            "123456789012345678",
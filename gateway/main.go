@@ -0,0 +1,139 @@
+package gateway
+
+import (
+	"runtime/debug"
+	"sync"
+
+	"github.com/Real-Dev-Squad/discord-service/config"
+	"github.com/Real-Dev-Squad/discord-service/dtos"
+	"github.com/Real-Dev-Squad/discord-service/metrics"
+	"github.com/Real-Dev-Squad/discord-service/queue"
+	"github.com/bwmarrin/discordgo"
+	"github.com/sirupsen/logrus"
+)
+
+// Gateway event types routed by the worker.
+const (
+	GuildMemberAdd    = "GUILD_MEMBER_ADD"
+	GuildMemberRemove = "GUILD_MEMBER_REMOVE"
+	GuildMemberUpdate = "GUILD_MEMBER_UPDATE"
+	GuildRoleUpdate   = "GUILD_ROLE_UPDATE"
+	GuildRoleDelete   = "GUILD_ROLE_DELETE"
+)
+
+// Intents the worker identifies with. Guild member events require the
+// privileged server members intent to be enabled for the bot.
+const Intents = discordgo.IntentsGuilds | discordgo.IntentsGuildMembers
+
+// Handler reacts to a gateway event. event is the typed payload discordgo
+// decoded, e.g. *discordgo.GuildMemberAdd. Every returned data packet is
+// published to the queue so that it is processed like a command.
+type Handler func(event interface{}) ([]*dtos.DataPacket, error)
+
+type SessionInterface interface {
+	AddHandler(handler interface{}) func()
+	Open() error
+	Close() error
+}
+
+// Worker keeps a gateway connection open and routes the events it receives
+// to the handlers registered for their type.
+type Worker struct {
+	session  SessionInterface
+	mu       sync.RWMutex
+	handlers map[string][]Handler
+	remove   func()
+}
+
+var NewDiscord = discordgo.New
+
+// NewSession creates a Discord session that identifies with Intents.
+func NewSession() (*discordgo.Session, error) {
+	session, err := NewDiscord("Bot " + config.AppConfig.BOT_TOKEN.Value())
+	if err != nil {
+		logrus.Errorf("Cannot create a new Discord gateway session: %v", err)
+		return nil, err
+	}
+	session.Identify.Intents = Intents
+	return session, nil
+}
+
+func NewWorker(session SessionInterface) *Worker {
+	return &Worker{
+		session:  session,
+		handlers: make(map[string][]Handler),
+	}
+}
+
+// Handle registers handler for events of eventType. Handlers run in the order
+// they were registered.
+func (w *Worker) Handle(eventType string, handler Handler) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.handlers[eventType] = append(w.handlers[eventType], handler)
+}
+
+// Start subscribes to every event and opens the gateway connection.
+func (w *Worker) Start() error {
+	w.remove = w.session.AddHandler(func(s *discordgo.Session, event *discordgo.Event) {
+		w.Dispatch(event)
+	})
+	if err := w.session.Open(); err != nil {
+		logrus.Errorf("Cannot open the gateway session: %v", err)
+		return err
+	}
+	logrus.Info("Listening to gateway events")
+	return nil
+}
+
+// Stop unsubscribes from events and closes the gateway connection.
+func (w *Worker) Stop() error {
+	if w.remove != nil {
+		w.remove()
+	}
+	return w.session.Close()
+}
+
+var Publish = queue.SendMessage
+
+// Dispatch runs the handlers registered for the event's type and publishes
+// the data packets they return.
+func (w *Worker) Dispatch(event *discordgo.Event) {
+	w.mu.RLock()
+	handlers := w.handlers[event.Type]
+	w.mu.RUnlock()
+
+	for _, handler := range handlers {
+		for _, dataPacket := range w.run(event, handler) {
+			bytePacket, err := dtos.ToByte(dataPacket)
+			if err != nil {
+				logrus.Errorf("Failed to encode %s for %s event: %v", dataPacket.CommandName, event.Type, err)
+				continue
+			}
+			if err := Publish(bytePacket); err != nil {
+				logrus.Errorf("Failed to publish %s for %s event: %v", dataPacket.CommandName, event.Type, err)
+			}
+		}
+	}
+}
+
+// run calls handler and keeps a failing or panicking handler from stopping the
+// ones registered after it.
+func (w *Worker) run(event *discordgo.Event, handler Handler) (dataPackets []*dtos.DataPacket) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			logrus.WithFields(logrus.Fields{
+				"event": event.Type,
+				"stack": string(debug.Stack()),
+			}).Errorf("Recovered from panic while handling gateway event: %v", recovered)
+			metrics.Errors.Add(metrics.GatewayPanic, 1)
+			dataPackets = nil
+		}
+	}()
+	dataPackets, err := handler(event.Struct)
+	if err != nil {
+		logrus.Errorf("Failed to handle %s event: %v", event.Type, err)
+		return nil
+	}
+	return dataPackets
+}
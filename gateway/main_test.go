@@ -0,0 +1,148 @@
+package gateway
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Real-Dev-Squad/discord-service/dtos"
+	_ "github.com/Real-Dev-Squad/discord-service/tests/helpers"
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockSession struct {
+	openError   error
+	handler     func(s *discordgo.Session, event *discordgo.Event)
+	removed     bool
+	closeCalled bool
+}
+
+func (m *mockSession) AddHandler(handler interface{}) func() {
+	m.handler = handler.(func(s *discordgo.Session, event *discordgo.Event))
+	return func() { m.removed = true }
+}
+
+func (m *mockSession) Open() error {
+	return m.openError
+}
+
+func (m *mockSession) Close() error {
+	m.closeCalled = true
+	return nil
+}
+
+func memberAddEvent(userID string) *discordgo.Event {
+	return &discordgo.Event{
+		Type: GuildMemberAdd,
+		Struct: &discordgo.GuildMemberAdd{
+			Member: &discordgo.Member{User: &discordgo.User{ID: userID}},
+		},
+	}
+}
+
+func capturePublished(t *testing.T) *[]*dtos.DataPacket {
+	originalPublish := Publish
+	t.Cleanup(func() { Publish = originalPublish })
+	published := &[]*dtos.DataPacket{}
+	Publish = func(message []byte) error {
+		dataPacket := &dtos.DataPacket{}
+		assert.NoError(t, dataPacket.FromByte(message))
+		*published = append(*published, dataPacket)
+		return nil
+	}
+	return published
+}
+
+func TestNewSession(t *testing.T) {
+	t.Run("should identify with the guild member intents", func(t *testing.T) {
+		session, err := NewSession()
+		assert.NoError(t, err)
+		assert.Equal(t, Intents, session.Identify.Intents)
+	})
+
+	t.Run("should return error if NewDiscord fails", func(t *testing.T) {
+		originalNewDiscord := NewDiscord
+		defer func() { NewDiscord = originalNewDiscord }()
+		NewDiscord = func(token string) (*discordgo.Session, error) {
+			return nil, errors.New("testing error")
+		}
+		_, err := NewSession()
+		assert.Error(t, err)
+	})
+}
+
+func TestWorker(t *testing.T) {
+	t.Run("should subscribe to events and open the session on start", func(t *testing.T) {
+		session := &mockSession{}
+		worker := NewWorker(session)
+		assert.NoError(t, worker.Start())
+		assert.NotNil(t, session.handler)
+
+		assert.NoError(t, worker.Stop())
+		assert.True(t, session.removed)
+		assert.True(t, session.closeCalled)
+	})
+
+	t.Run("should return error if the session cannot be opened", func(t *testing.T) {
+		worker := NewWorker(&mockSession{openError: errors.New("open error")})
+		assert.Error(t, worker.Start())
+	})
+
+	t.Run("should route events received from the session to their handlers", func(t *testing.T) {
+		published := capturePublished(t)
+		session := &mockSession{}
+		worker := NewWorker(session)
+		worker.Handle(GuildMemberAdd, func(event interface{}) ([]*dtos.DataPacket, error) {
+			member := event.(*discordgo.GuildMemberAdd)
+			return []*dtos.DataPacket{{UserID: member.User.ID, CommandName: "memberJoined"}}, nil
+		})
+		assert.NoError(t, worker.Start())
+
+		session.handler(nil, memberAddEvent("1"))
+		assert.Len(t, *published, 1)
+		assert.Equal(t, "1", (*published)[0].UserID)
+		assert.Equal(t, "memberJoined", (*published)[0].CommandName)
+	})
+
+	t.Run("should ignore events without handlers", func(t *testing.T) {
+		published := capturePublished(t)
+		worker := NewWorker(&mockSession{})
+		worker.Handle(GuildMemberRemove, func(event interface{}) ([]*dtos.DataPacket, error) {
+			return []*dtos.DataPacket{{CommandName: "memberLeft"}}, nil
+		})
+		worker.Dispatch(memberAddEvent("1"))
+		assert.Empty(t, *published)
+	})
+
+	t.Run("should keep running later handlers when one fails or panics", func(t *testing.T) {
+		published := capturePublished(t)
+		worker := NewWorker(&mockSession{})
+		worker.Handle(GuildMemberAdd, func(event interface{}) ([]*dtos.DataPacket, error) {
+			return nil, errors.New("handler error")
+		})
+		worker.Handle(GuildMemberAdd, func(event interface{}) ([]*dtos.DataPacket, error) {
+			panic("boom")
+		})
+		worker.Handle(GuildMemberAdd, func(event interface{}) ([]*dtos.DataPacket, error) {
+			return []*dtos.DataPacket{{CommandName: "memberJoined"}}, nil
+		})
+		assert.NotPanics(t, func() { worker.Dispatch(memberAddEvent("1")) })
+		assert.Len(t, *published, 1)
+	})
+
+	t.Run("should not stop dispatching when publishing fails", func(t *testing.T) {
+		originalPublish := Publish
+		defer func() { Publish = originalPublish }()
+		attempts := 0
+		Publish = func(message []byte) error {
+			attempts++
+			return errors.New("queue error")
+		}
+		worker := NewWorker(&mockSession{})
+		worker.Handle(GuildMemberAdd, func(event interface{}) ([]*dtos.DataPacket, error) {
+			return []*dtos.DataPacket{{CommandName: "a"}, {CommandName: "b"}}, nil
+		})
+		worker.Dispatch(memberAddEvent("1"))
+		assert.Equal(t, 2, attempts)
+	})
+}
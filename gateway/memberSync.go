@@ -0,0 +1,53 @@
+package gateway
+
+import (
+	"time"
+
+	"github.com/Real-Dev-Squad/discord-service/config"
+	"github.com/Real-Dev-Squad/discord-service/dtos"
+	"github.com/Real-Dev-Squad/discord-service/utils"
+	"github.com/bwmarrin/discordgo"
+)
+
+var Now = time.Now
+
+// MemberJoined enqueues a member sync when someone joins GUILD_ID.
+func MemberJoined(event interface{}) ([]*dtos.DataPacket, error) {
+	memberAdd, ok := event.(*discordgo.GuildMemberAdd)
+	if !ok || memberAdd.Member == nil || memberAdd.User == nil || memberAdd.GuildID != config.AppConfig.GUILD_ID {
+		return nil, nil
+	}
+	return []*dtos.DataPacket{memberSyncPacket(memberAdd.Member, "")}, nil
+}
+
+// MemberLeft enqueues a member sync when someone leaves GUILD_ID.
+func MemberLeft(event interface{}) ([]*dtos.DataPacket, error) {
+	memberRemove, ok := event.(*discordgo.GuildMemberRemove)
+	if !ok || memberRemove.Member == nil || memberRemove.User == nil || memberRemove.GuildID != config.AppConfig.GUILD_ID {
+		return nil, nil
+	}
+	return []*dtos.DataPacket{memberSyncPacket(memberRemove.Member, Now().Format(time.RFC3339))}, nil
+}
+
+// memberSyncPacket leaves discordJoinedAt out when the event has no join
+// time, as GUILD_MEMBER_REMOVE does not, so the stored one is kept.
+func memberSyncPacket(member *discordgo.Member, leftAt string) *dtos.DataPacket {
+	metaData := map[string]string{
+		"userName":      member.User.Username,
+		"discordLeftAt": leftAt,
+	}
+	if !member.JoinedAt.IsZero() {
+		metaData["discordJoinedAt"] = member.JoinedAt.Format(time.RFC3339)
+	}
+	return &dtos.DataPacket{
+		UserID:      member.User.ID,
+		CommandName: utils.CommandNames.MemberSync,
+		MetaData:    metaData,
+	}
+}
+
+// RegisterMemberSync routes guild join and leave events to the member sync.
+func RegisterMemberSync(worker *Worker) {
+	worker.Handle(GuildMemberAdd, MemberJoined)
+	worker.Handle(GuildMemberRemove, MemberLeft)
+}
@@ -0,0 +1,86 @@
+package gateway
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Real-Dev-Squad/discord-service/config"
+	"github.com/Real-Dev-Squad/discord-service/utils"
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemberJoined(t *testing.T) {
+	joinedAt, _ := time.Parse(time.RFC3339, "2024-01-01T00:00:00Z")
+	member := &discordgo.Member{
+		GuildID:  config.AppConfig.GUILD_ID,
+		JoinedAt: joinedAt,
+		User:     &discordgo.User{ID: "1", Username: "joy"},
+	}
+
+	t.Run("should enqueue a member sync for members joining the guild", func(t *testing.T) {
+		dataPackets, err := MemberJoined(&discordgo.GuildMemberAdd{Member: member})
+		assert.NoError(t, err)
+		assert.Len(t, dataPackets, 1)
+		assert.Equal(t, "1", dataPackets[0].UserID)
+		assert.Equal(t, utils.CommandNames.MemberSync, dataPackets[0].CommandName)
+		assert.Equal(t, "joy", dataPackets[0].MetaData["userName"])
+		assert.Equal(t, "2024-01-01T00:00:00Z", dataPackets[0].MetaData["discordJoinedAt"])
+		assert.Empty(t, dataPackets[0].MetaData["discordLeftAt"])
+	})
+
+	t.Run("should ignore members joining other guilds", func(t *testing.T) {
+		other := *member
+		other.GuildID = "other"
+		dataPackets, err := MemberJoined(&discordgo.GuildMemberAdd{Member: &other})
+		assert.NoError(t, err)
+		assert.Empty(t, dataPackets)
+	})
+
+	t.Run("should ignore other events", func(t *testing.T) {
+		dataPackets, err := MemberJoined(&discordgo.GuildMemberRemove{Member: member})
+		assert.NoError(t, err)
+		assert.Empty(t, dataPackets)
+	})
+}
+
+func TestMemberLeft(t *testing.T) {
+	originalNow := Now
+	defer func() { Now = originalNow }()
+	leftAt, _ := time.Parse(time.RFC3339, "2024-02-01T00:00:00Z")
+	Now = func() time.Time { return leftAt }
+
+	t.Run("should enqueue a member sync with the time the member left", func(t *testing.T) {
+		dataPackets, err := MemberLeft(&discordgo.GuildMemberRemove{Member: &discordgo.Member{
+			GuildID: config.AppConfig.GUILD_ID,
+			User:    &discordgo.User{ID: "1", Username: "joy"},
+		}})
+		assert.NoError(t, err)
+		assert.Len(t, dataPackets, 1)
+		assert.Equal(t, "2024-02-01T00:00:00Z", dataPackets[0].MetaData["discordLeftAt"])
+		assert.NotContains(t, dataPackets[0].MetaData, "discordJoinedAt")
+	})
+
+	t.Run("should ignore members without a user", func(t *testing.T) {
+		dataPackets, err := MemberLeft(&discordgo.GuildMemberRemove{Member: &discordgo.Member{GuildID: config.AppConfig.GUILD_ID}})
+		assert.NoError(t, err)
+		assert.Empty(t, dataPackets)
+	})
+}
+
+func TestRegisterMemberSync(t *testing.T) {
+	t.Run("should route join and leave events to the member sync", func(t *testing.T) {
+		published := capturePublished(t)
+		worker := NewWorker(&mockSession{})
+		RegisterMemberSync(worker)
+		worker.Dispatch(&discordgo.Event{Type: GuildMemberAdd, Struct: &discordgo.GuildMemberAdd{Member: &discordgo.Member{
+			GuildID: config.AppConfig.GUILD_ID,
+			User:    &discordgo.User{ID: "1"},
+		}}})
+		worker.Dispatch(&discordgo.Event{Type: GuildMemberRemove, Struct: &discordgo.GuildMemberRemove{Member: &discordgo.Member{
+			GuildID: config.AppConfig.GUILD_ID,
+			User:    &discordgo.User{ID: "1"},
+		}}})
+		assert.Len(t, *published, 2)
+	})
+}
@@ -0,0 +1,50 @@
+package i18n
+
+import "github.com/bwmarrin/discordgo"
+
+// catalog holds the messages shown to Discord users. Every key must have a
+// DefaultLocale message, other locales may leave keys out.
+var catalog = map[discordgo.Locale]map[Key]string{
+	discordgo.EnglishUS: {
+		HelloGreeting:           "Hey there <@%s>! Congratulations, you just executed your first slash command",
+		ListeningAlreadyEnabled: "You are already set to listen.",
+		ListeningUnchanged:      "Your nickname remains unchanged.",
+		ListeningUpdating:       "Your nickname will be updated shortly.",
+		RequestProcessing:       "Your request is being processed.",
+		VerifyLink:              "Please verify your discord account by clicking the link below 👇",
+		VerifyNote:              "By granting authorization, you agree to permit us to manage your server nickname displayed ONLY in the Real Dev Squad server and to sync your joining data with your user account on our platform.",
+		VerifyLinkFailed:        "Something went wrong while generating verification link",
+		VerifyCompleted:         "Your Discord account is now linked to your Real Dev Squad account. Welcome aboard!",
+		CommandDMNotAllowed:     "This command can't be used in direct messages.",
+		CommandOtherGuild:       "This command is only available in the Real Dev Squad server.",
+		CommandUnknown:          "This command is not supported.",
+		CommandCooldown:         "You can use /%s again in %s.",
+		CommandDisabled:         "/%s is temporarily disabled. Please try again later.",
+		ErrorDefault:            "Something went wrong while processing your command. Please try again later.",
+		ErrorBadRequest:         "This command could not be processed. Please check the options and try again.",
+		ErrorNotAllowed:         "You are not allowed to use this command.",
+		ErrorTooManyRequests:    "You are doing that too often. Please try again later.",
+		ErrorInternal:           "Something went wrong (ref: %s)",
+	},
+	discordgo.Hindi: {
+		HelloGreeting:           "नमस्ते <@%s>! बधाई हो, आपने अपना पहला स्लैश कमांड चला लिया है",
+		ListeningAlreadyEnabled: "आप पहले से ही सुनने के लिए सेट हैं।",
+		ListeningUnchanged:      "आपका निकनेम पहले जैसा ही रहेगा।",
+		ListeningUpdating:       "आपका निकनेम जल्द ही अपडेट कर दिया जाएगा।",
+		RequestProcessing:       "आपके अनुरोध पर काम किया जा रहा है।",
+		VerifyLink:              "कृपया नीचे दिए गए लिंक पर क्लिक करके अपना Discord खाता सत्यापित करें 👇",
+		VerifyNote:              "अनुमति देकर, आप हमें केवल Real Dev Squad सर्वर में दिखने वाले आपके निकनेम को प्रबंधित करने और आपके जुड़ने की जानकारी को हमारे प्लेटफ़ॉर्म पर आपके खाते के साथ सिंक करने की अनुमति देते हैं।",
+		VerifyLinkFailed:        "सत्यापन लिंक बनाते समय कुछ गड़बड़ हो गई",
+		VerifyCompleted:         "आपका Discord खाता अब आपके Real Dev Squad खाते से जुड़ गया है। स्वागत है!",
+		CommandDMNotAllowed:     "इस कमांड का उपयोग डायरेक्ट मैसेज में नहीं किया जा सकता।",
+		CommandOtherGuild:       "यह कमांड केवल Real Dev Squad सर्वर में उपलब्ध है।",
+		CommandUnknown:          "यह कमांड समर्थित नहीं है।",
+		CommandCooldown:         "आप /%s का उपयोग %s बाद फिर से कर सकते हैं।",
+		CommandDisabled:         "/%s अस्थायी रूप से बंद है। कृपया बाद में फिर से प्रयास करें।",
+		ErrorDefault:            "आपका कमांड प्रोसेस करते समय कुछ गड़बड़ हो गई। कृपया बाद में फिर से प्रयास करें।",
+		ErrorBadRequest:         "यह कमांड प्रोसेस नहीं हो सका। कृपया विकल्प जाँचें और फिर से प्रयास करें।",
+		ErrorNotAllowed:         "आपको इस कमांड का उपयोग करने की अनुमति नहीं है।",
+		ErrorTooManyRequests:    "आप यह बहुत बार कर रहे हैं। कृपया कुछ देर बाद फिर से प्रयास करें।",
+		ErrorInternal:           "कुछ गड़बड़ हो गई (ref: %s)",
+	},
+}
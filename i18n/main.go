@@ -0,0 +1,119 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultLocale has every message and is used for anything missing in the
+// locale of an interaction.
+const DefaultLocale = discordgo.EnglishUS
+
+// Key names a message in the catalog.
+type Key string
+
+const (
+	HelloGreeting           Key = "hello.greeting"
+	ListeningAlreadyEnabled Key = "listening.already_enabled"
+	ListeningUnchanged      Key = "listening.unchanged"
+	ListeningUpdating       Key = "listening.updating"
+	RequestProcessing       Key = "request.processing"
+	VerifyLink              Key = "verify.link"
+	VerifyNote              Key = "verify.note"
+	VerifyLinkFailed        Key = "verify.link_failed"
+	VerifyCompleted         Key = "verify.completed"
+	CommandDMNotAllowed     Key = "command.dm_not_allowed"
+	CommandOtherGuild       Key = "command.other_guild"
+	CommandUnknown          Key = "command.unknown"
+	CommandCooldown         Key = "command.cooldown"
+	CommandDisabled         Key = "command.disabled"
+	ErrorDefault            Key = "error.default"
+	ErrorBadRequest         Key = "error.bad_request"
+	ErrorNotAllowed         Key = "error.not_allowed"
+	ErrorTooManyRequests    Key = "error.too_many_requests"
+	ErrorInternal           Key = "error.internal"
+)
+
+// Keys lists every Key, so tests can check that each one has a DefaultLocale
+// message.
+var Keys = []Key{
+	HelloGreeting,
+	ListeningAlreadyEnabled,
+	ListeningUnchanged,
+	ListeningUpdating,
+	RequestProcessing,
+	VerifyLink,
+	VerifyNote,
+	VerifyLinkFailed,
+	VerifyCompleted,
+	CommandDMNotAllowed,
+	CommandOtherGuild,
+	CommandUnknown,
+	CommandCooldown,
+	CommandDisabled,
+	ErrorDefault,
+	ErrorBadRequest,
+	ErrorNotAllowed,
+	ErrorTooManyRequests,
+	ErrorInternal,
+}
+
+// T returns the message for key in locale, formatted with args. Messages
+// missing in locale fall back to DefaultLocale.
+func T(locale discordgo.Locale, key Key, args ...any) string {
+	message, ok := catalog[Resolve(locale)][key]
+	if !ok {
+		message, ok = catalog[DefaultLocale][key]
+	}
+	if !ok {
+		logrus.Warnf("Message %s is missing from the catalog", key)
+		message = string(key)
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// Resolve picks the catalog locale for a Discord locale: the same locale, else
+// one of the same language, else DefaultLocale.
+func Resolve(locale discordgo.Locale) discordgo.Locale {
+	if _, ok := catalog[locale]; ok {
+		return locale
+	}
+	language, _, _ := strings.Cut(string(locale), "-")
+	if language == "" {
+		return DefaultLocale
+	}
+	for _, candidate := range Locales() {
+		if prefix, _, _ := strings.Cut(string(candidate), "-"); prefix == language {
+			return candidate
+		}
+	}
+	return DefaultLocale
+}
+
+// Locales returns the locales of the catalog in order.
+func Locales() []discordgo.Locale {
+	locales := make([]discordgo.Locale, 0, len(catalog))
+	for locale := range catalog {
+		locales = append(locales, locale)
+	}
+	sort.Slice(locales, func(i, j int) bool { return locales[i] < locales[j] })
+	return locales
+}
+
+// Pick returns the first locale that is set, in order of preference, such as
+// the user's locale followed by the guild's.
+func Pick(locales ...discordgo.Locale) discordgo.Locale {
+	for _, locale := range locales {
+		if locale != "" {
+			return locale
+		}
+	}
+	return DefaultLocale
+}
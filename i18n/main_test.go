@@ -0,0 +1,108 @@
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalog(t *testing.T) {
+	t.Run("should have a default message for every key", func(t *testing.T) {
+		for _, key := range Keys {
+			assert.NotEmpty(t, catalog[DefaultLocale][key], "%s has no %s message", key, DefaultLocale)
+		}
+		for locale, messages := range catalog {
+			for key := range messages {
+				assert.Contains(t, catalog[DefaultLocale], key, "%s has %s but %s does not", locale, key, DefaultLocale)
+			}
+		}
+	})
+
+	t.Run("should list every declared key", func(t *testing.T) {
+		file, err := parser.ParseFile(token.NewFileSet(), "main.go", nil, 0)
+		assert.NoError(t, err)
+		declared := 0
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				value := spec.(*ast.ValueSpec)
+				if ident, ok := value.Type.(*ast.Ident); !ok || ident.Name != "Key" {
+					continue
+				}
+				for _, name := range value.Names {
+					declared++
+					assert.Contains(t, Keys, Key(strings.Trim(value.Values[0].(*ast.BasicLit).Value, `"`)), "%s is missing from Keys", name.Name)
+				}
+			}
+		}
+		assert.Len(t, Keys, declared)
+	})
+
+	t.Run("should take the same arguments in every locale", func(t *testing.T) {
+		for locale, messages := range catalog {
+			for key, message := range messages {
+				assert.Equal(t, strings.Count(catalog[DefaultLocale][key], "%"), strings.Count(message, "%"), "%s of %s", key, locale)
+			}
+		}
+	})
+
+	t.Run("should only use locales Discord supports", func(t *testing.T) {
+		for locale := range catalog {
+			assert.Contains(t, discordgo.Locales, locale)
+		}
+	})
+}
+
+func TestT(t *testing.T) {
+	t.Run("should format the message of the locale", func(t *testing.T) {
+		assert.Equal(t, "नमस्ते <@1>! बधाई हो, आपने अपना पहला स्लैश कमांड चला लिया है", T(discordgo.Hindi, HelloGreeting, "1"))
+		assert.Equal(t, "You can use /verify again in 30s.", T(DefaultLocale, CommandCooldown, "verify", "30s"))
+	})
+
+	t.Run("should fall back to the default locale for a missing message", func(t *testing.T) {
+		hindi := catalog[discordgo.Hindi]
+		defer func() { catalog[discordgo.Hindi] = hindi }()
+		catalog[discordgo.Hindi] = map[Key]string{}
+		assert.Equal(t, catalog[DefaultLocale][CommandUnknown], T(discordgo.Hindi, CommandUnknown))
+	})
+
+	t.Run("should fall back to the key for an unknown message", func(t *testing.T) {
+		assert.Equal(t, "unknown.key", T(DefaultLocale, Key("unknown.key")))
+	})
+}
+
+func TestResolve(t *testing.T) {
+	t.Run("should use a supported locale as is", func(t *testing.T) {
+		assert.Equal(t, discordgo.Hindi, Resolve(discordgo.Hindi))
+	})
+	t.Run("should use a locale of the same language", func(t *testing.T) {
+		assert.Equal(t, discordgo.EnglishUS, Resolve(discordgo.EnglishGB))
+	})
+	t.Run("should use the default locale otherwise", func(t *testing.T) {
+		assert.Equal(t, DefaultLocale, Resolve(discordgo.German))
+		assert.Equal(t, DefaultLocale, Resolve(""))
+	})
+}
+
+func TestLocales(t *testing.T) {
+	t.Run("should list the locales of the catalog in order", func(t *testing.T) {
+		assert.Equal(t, []discordgo.Locale{discordgo.EnglishUS, discordgo.Hindi}, Locales())
+	})
+}
+
+func TestPick(t *testing.T) {
+	t.Run("should prefer the first locale that is set", func(t *testing.T) {
+		assert.Equal(t, discordgo.Hindi, Pick("", discordgo.Hindi, discordgo.EnglishGB))
+	})
+	t.Run("should use the default locale when none is set", func(t *testing.T) {
+		assert.Equal(t, DefaultLocale, Pick("", ""))
+	})
+}
@@ -0,0 +1,95 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// NackGrace is how long work abandoned at the drain deadline gets to hand its
+// jobs back before the parts still running are stopped regardless.
+const NackGrace = 5 * time.Second
+
+// Manager stops the parts of the service in the reverse order they were
+// started once the service is asked to shut down.
+type Manager struct {
+	mu    sync.Mutex
+	hooks []hook
+	work  context.Context
+	abort context.CancelFunc
+}
+
+type hook struct {
+	name string
+	stop func(ctx context.Context) error
+}
+
+func New() *Manager {
+	work, abort := context.WithCancel(context.Background())
+	return &Manager{work: work, abort: abort}
+}
+
+// Context is done once the drain deadline passed. Work still running then
+// should give up and nack its job so that it is delivered again.
+func (m *Manager) Context() context.Context {
+	return m.work
+}
+
+// OnStop registers how to stop a part that was just started. stop is given
+// until the drain deadline plus NackGrace.
+func (m *Manager) OnStop(name string, stop func(ctx context.Context) error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, hook{name: name, stop: stop})
+}
+
+// Run blocks until one of signals is received or failed reports an error, then
+// shuts down with drain as the drain deadline. The error from failed is
+// returned along with any error from stopping.
+func (m *Manager) Run(failed <-chan error, drain time.Duration, signals ...os.Signal) error {
+	received := make(chan os.Signal, 1)
+	signal.Notify(received, signals...)
+	defer signal.Stop(received)
+
+	var cause error
+	select {
+	case sig := <-received:
+		logrus.Infof("Received %s, shutting down", sig)
+	case cause = <-failed:
+		logrus.Errorf("Shutting down after a failure: %v", cause)
+	}
+	return errors.Join(cause, m.Shutdown(drain))
+}
+
+// Shutdown runs the stop hooks in the reverse order they were registered. In
+// flight work has until drain to finish, then Context is cancelled.
+func (m *Manager) Shutdown(drain time.Duration) error {
+	m.mu.Lock()
+	hooks := m.hooks
+	m.hooks = nil
+	m.mu.Unlock()
+
+	abortTimer := time.AfterFunc(drain, m.abort)
+	defer abortTimer.Stop()
+	defer m.abort()
+	ctx, cancel := context.WithTimeout(context.Background(), drain+NackGrace)
+	defer cancel()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		started := time.Now()
+		if err := hooks[i].stop(ctx); err != nil {
+			logrus.Errorf("Failed to stop %s: %v", hooks[i].name, err)
+			errs = append(errs, fmt.Errorf("stopping %s: %w", hooks[i].name, err))
+			continue
+		}
+		logrus.Infof("Stopped %s in %s", hooks[i].name, time.Since(started).Round(time.Millisecond))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,100 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestShutdown(t *testing.T) {
+	t.Run("should stop parts in the reverse order they were started", func(t *testing.T) {
+		manager := New()
+		stopped := []string{}
+		for _, name := range []string{"discord", "queue", "http"} {
+			manager.OnStop(name, func(context.Context) error {
+				stopped = append(stopped, name)
+				return nil
+			})
+		}
+		assert.NoError(t, manager.Shutdown(time.Second))
+		assert.Equal(t, []string{"http", "queue", "discord"}, stopped)
+	})
+
+	t.Run("should keep stopping the other parts when one fails", func(t *testing.T) {
+		manager := New()
+		stopped := false
+		manager.OnStop("discord", func(context.Context) error { stopped = true; return nil })
+		manager.OnStop("queue", func(context.Context) error { return errors.New("connection reset") })
+		err := manager.Shutdown(time.Second)
+		assert.ErrorContains(t, err, "stopping queue: connection reset")
+		assert.True(t, stopped)
+	})
+
+	t.Run("should let work finish until the drain deadline", func(t *testing.T) {
+		manager := New()
+		manager.OnStop("http", func(context.Context) error {
+			assert.NoError(t, manager.Context().Err())
+			return nil
+		})
+		assert.NoError(t, manager.Shutdown(time.Second))
+		assert.Error(t, manager.Context().Err())
+	})
+
+	t.Run("should tell work to give up at the drain deadline and wait for it to nack", func(t *testing.T) {
+		manager := New()
+		work := manager.Context()
+		nacked := false
+		manager.OnStop("http", func(ctx context.Context) error {
+			select {
+			case <-work.Done():
+				nacked = true
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+		start := time.Now()
+		assert.NoError(t, manager.Shutdown(10*time.Millisecond))
+		assert.True(t, nacked)
+		assert.Less(t, time.Since(start), NackGrace)
+	})
+}
+
+func TestRun(t *testing.T) {
+	t.Run("should shut down when a signal is received", func(t *testing.T) {
+		manager := New()
+		stopped := make(chan struct{})
+		manager.OnStop("http", func(context.Context) error { close(stopped); return nil })
+		// Keep SIGUSR1 from killing the test binary before Run listens for it.
+		ignored := make(chan os.Signal, 1)
+		signal.Notify(ignored, syscall.SIGUSR1)
+		defer signal.Stop(ignored)
+		go func() {
+			for {
+				select {
+				case <-stopped:
+					return
+				case <-time.After(10 * time.Millisecond):
+					syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+				}
+			}
+		}()
+		assert.NoError(t, manager.Run(make(chan error), time.Second, syscall.SIGUSR1))
+	})
+
+	t.Run("should shut down and return the failure of a part", func(t *testing.T) {
+		manager := New()
+		stopped := false
+		manager.OnStop("http", func(context.Context) error { stopped = true; return nil })
+		failed := make(chan error, 1)
+		failed <- errors.New("address already in use")
+		assert.ErrorContains(t, manager.Run(failed, time.Second, syscall.SIGUSR1), "address already in use")
+		assert.True(t, stopped)
+	})
+}
@@ -1,16 +1,14 @@
 package main
 
 import (
-	"github.com/Real-Dev-Squad/discord-service/commands/register"
-	config "github.com/Real-Dev-Squad/discord-service/config"
-	queue "github.com/Real-Dev-Squad/discord-service/queue"
-	"github.com/Real-Dev-Squad/discord-service/routes"
+	"os"
+
+	"github.com/Real-Dev-Squad/discord-service/cmd"
 	"github.com/sirupsen/logrus"
 )
 
 func main() {
-	register.SetupRegister()
-	logrus.Info("Starting server on port " + config.AppConfig.Port)
-	queue.GetQueueInstance()
-	routes.Listen(":" + config.AppConfig.Port)
+	if err := cmd.Run(os.Args[1:], os.Stdout); err != nil {
+		logrus.Fatal(err)
+	}
 }
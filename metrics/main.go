@@ -0,0 +1,24 @@
+package metrics
+
+import "expvar"
+
+// Errors counts failures by kind. The counters are published through expvar
+// and served on /debug/vars to requests with a service token.
+var Errors = expvar.NewMap("errors")
+
+const (
+	InteractionPanic = "interaction_panic"
+	QueuePanic       = "queue_panic"
+	GatewayPanic     = "gateway_panic"
+)
+
+// DiscordRateLimit counts how often calls to the Discord API were held back
+// by rate limits and for how long.
+var DiscordRateLimit = expvar.NewMap("discord_rate_limit")
+
+const (
+	RateLimitWaits            = "waits"
+	RateLimitWaitMilliseconds = "wait_ms"
+	RateLimitRetries          = "retries"
+	RateLimitGlobal           = "global"
+)
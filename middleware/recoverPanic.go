@@ -0,0 +1,119 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"runtime/debug"
+
+	"github.com/Real-Dev-Squad/discord-service/commands/handlers"
+	"github.com/Real-Dev-Squad/discord-service/dtos"
+	"github.com/Real-Dev-Squad/discord-service/i18n"
+	"github.com/Real-Dev-Squad/discord-service/metrics"
+	"github.com/Real-Dev-Squad/discord-service/utils"
+	"github.com/bwmarrin/discordgo"
+	"github.com/google/uuid"
+	"github.com/julienschmidt/httprouter"
+	"github.com/sirupsen/logrus"
+)
+
+var SendEphemeralFollowUp = handlers.SendEphemeralFollowUp
+
+// responseRecorder remembers whether the wrapped handler already started its response.
+type responseRecorder struct {
+	http.ResponseWriter
+	wroteHeader bool
+}
+
+func (r *responseRecorder) WriteHeader(statusCode int) {
+	r.wroteHeader = true
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(b)
+}
+
+// bufferBody reads the request body so that it can be inspected after the
+// handler has consumed it.
+func bufferBody(request *http.Request) []byte {
+	if request.Body == nil {
+		return nil
+	}
+	payload, err := io.ReadAll(request.Body)
+	request.Body.Close()
+	if err != nil {
+		logrus.Errorf("Failed to buffer request body: %v", err)
+	}
+	request.Body = io.NopCloser(bytes.NewReader(payload))
+	return payload
+}
+
+// RecoverInteraction turns a panic raised while handling an interaction into an
+// ephemeral reply carrying a reference ID that can be looked up in the logs.
+func RecoverInteraction(next httprouter.Handle) httprouter.Handle {
+	return func(response http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		payload := bufferBody(request)
+		recorder := &responseRecorder{ResponseWriter: response}
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			ref := uuid.NewString()
+			message := dtos.DiscordMessage{}
+			_ = json.Unmarshal(payload, &message)
+			logrus.WithFields(logrus.Fields{
+				"ref":           ref,
+				"interactionId": message.ID,
+				"channelId":     message.ChannelId,
+				"stack":         string(debug.Stack()),
+			}).Errorf("Recovered from panic while handling interaction: %v", recovered)
+			metrics.Errors.Add(metrics.InteractionPanic, 1)
+
+			if recorder.wroteHeader {
+				return
+			}
+			utils.WriteJSONResponse(recorder, http.StatusOK, dtos.NewEphemeralResponse(i18n.T(message.PreferredLocale(), i18n.ErrorInternal, ref)))
+		}()
+		next(recorder, request, params)
+	}
+}
+
+// RecoverQueue turns a panic raised while processing a queued job into an
+// ephemeral follow-up on the interaction that enqueued it, and acknowledges the
+// delivery so that the job is not retried.
+func RecoverQueue(next httprouter.Handle) httprouter.Handle {
+	return func(response http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		payload := bufferBody(request)
+		recorder := &responseRecorder{ResponseWriter: response}
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			ref := uuid.NewString()
+			dataPacket := &dtos.DataPacket{}
+			_ = json.Unmarshal(payload, dataPacket)
+			logrus.WithFields(logrus.Fields{
+				"ref":           ref,
+				"commandName":   dataPacket.CommandName,
+				"userId":        dataPacket.UserID,
+				"interactionId": dataPacket.MetaData["interactionId"],
+				"stack":         string(debug.Stack()),
+			}).Errorf("Recovered from panic while processing queued job: %v", recovered)
+			metrics.Errors.Add(metrics.QueuePanic, 1)
+
+			if err := SendEphemeralFollowUp(dataPacket, i18n.T(discordgo.Locale(dataPacket.MetaData["locale"]), i18n.ErrorInternal, ref)); err != nil {
+				logrus.Errorf("Failed to notify user about job %s: %v", ref, err)
+			}
+			if !recorder.wroteHeader {
+				recorder.Header().Set("Content-Type", "application/json")
+				recorder.WriteHeader(http.StatusOK)
+			}
+		}()
+		next(recorder, request, params)
+	}
+}
@@ -0,0 +1,126 @@
+package middleware_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Real-Dev-Squad/discord-service/dtos"
+	"github.com/Real-Dev-Squad/discord-service/metrics"
+	"github.com/Real-Dev-Squad/discord-service/middleware"
+	_ "github.com/Real-Dev-Squad/discord-service/tests/helpers"
+	"github.com/bwmarrin/discordgo"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+func panickingController(response http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	io.ReadAll(request.Body)
+	panic("boom")
+}
+
+func panicCount(name string) int64 {
+	if v := metrics.Errors.Get(name); v != nil {
+		return v.(interface{ Value() int64 }).Value()
+	}
+	return 0
+}
+
+func TestRecoverInteraction(t *testing.T) {
+	t.Run("should pass through when the handler does not panic", func(t *testing.T) {
+		router := setup()
+		router.POST("/safe", middleware.RecoverInteraction(testController))
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/safe", nil)
+		router.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, testControllerCalled)
+	})
+
+	t.Run("should reply with an ephemeral message when the handler panics", func(t *testing.T) {
+		before := panicCount(metrics.InteractionPanic)
+		router := httprouter.New()
+		router.POST("/", middleware.RecoverInteraction(panickingController))
+		body, _ := json.Marshal(dtos.DiscordMessage{ID: "interaction-1", Type: discordgo.InteractionApplicationCommand})
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/", bytes.NewBuffer(body))
+		assert.NotPanics(t, func() { router.ServeHTTP(w, r) })
+
+		response := discordgo.InteractionResponse{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, response.Data.Content, "Something went wrong (ref: ")
+		assert.Equal(t, discordgo.MessageFlagsEphemeral, response.Data.Flags)
+		assert.Equal(t, before+1, panicCount(metrics.InteractionPanic))
+	})
+
+	t.Run("should reply in the locale of the user when the handler panics", func(t *testing.T) {
+		router := httprouter.New()
+		router.POST("/", middleware.RecoverInteraction(panickingController))
+		body, _ := json.Marshal(dtos.DiscordMessage{ID: "interaction-1", Type: discordgo.InteractionApplicationCommand, Locale: discordgo.Hindi})
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/", bytes.NewBuffer(body))
+		router.ServeHTTP(w, r)
+
+		response := discordgo.InteractionResponse{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Contains(t, response.Data.Content, "कुछ गड़बड़ हो गई (ref: ")
+	})
+
+	t.Run("should not write a second response when the handler panics after responding", func(t *testing.T) {
+		router := httprouter.New()
+		router.POST("/", middleware.RecoverInteraction(func(response http.ResponseWriter, request *http.Request, params httprouter.Params) {
+			response.WriteHeader(http.StatusAccepted)
+			panic("boom")
+		}))
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/", nil)
+		router.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Empty(t, w.Body.String())
+	})
+}
+
+func TestRecoverQueue(t *testing.T) {
+	originalFollowUp := middleware.SendEphemeralFollowUp
+	defer func() { middleware.SendEphemeralFollowUp = originalFollowUp }()
+
+	t.Run("should acknowledge the job and send a follow-up when the handler panics", func(t *testing.T) {
+		before := panicCount(metrics.QueuePanic)
+		var notified *dtos.DataPacket
+		var content string
+		middleware.SendEphemeralFollowUp = func(dataPacket *dtos.DataPacket, message string) error {
+			notified = dataPacket
+			content = message
+			return nil
+		}
+		router := httprouter.New()
+		router.POST("/queue", middleware.RecoverQueue(panickingController))
+		body, _ := dtos.ToByte(&dtos.DataPacket{UserID: "1", CommandName: "verify", MetaData: map[string]string{"token": "token"}})
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/queue", bytes.NewBuffer(body))
+		assert.NotPanics(t, func() { router.ServeHTTP(w, r) })
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotNil(t, notified)
+		assert.Equal(t, "verify", notified.CommandName)
+		assert.Contains(t, content, "Something went wrong (ref: ")
+		assert.Equal(t, before+1, panicCount(metrics.QueuePanic))
+	})
+
+	t.Run("should still acknowledge the job when the follow-up fails", func(t *testing.T) {
+		middleware.SendEphemeralFollowUp = func(dataPacket *dtos.DataPacket, message string) error {
+			return errors.New("follow-up error")
+		}
+		router := httprouter.New()
+		router.POST("/queue", middleware.RecoverQueue(panickingController))
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/queue", bytes.NewBufferString("{}"))
+		router.ServeHTTP(w, r)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"sync"
+	"time"
+)
+
+// replayCache remembers interaction IDs until they are too old to pass the
+// timestamp check, so that a captured request cannot be delivered twice.
+type replayCache struct {
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+func newReplayCache() *replayCache {
+	return &replayCache{seen: make(map[string]time.Time)}
+}
+
+// CheckAndStore records id and reports whether it had already been recorded
+// and not yet expired.
+func (c *replayCache) CheckAndStore(id string, now time.Time, ttl time.Duration) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, expiry := range c.seen {
+		if now.After(expiry) {
+			delete(c.seen, key)
+		}
+	}
+	if _, ok := c.seen[id]; ok {
+		return true
+	}
+	c.seen[id] = now.Add(ttl)
+	return false
+}
+
+// Reset forgets every recorded interaction.
+func (c *replayCache) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.seen = make(map[string]time.Time)
+}
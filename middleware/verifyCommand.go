@@ -2,7 +2,10 @@ package middleware
 
 import (
 	"encoding/hex"
+	"encoding/json"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/Real-Dev-Squad/discord-service/config"
 	"github.com/Real-Dev-Squad/discord-service/errors"
@@ -12,22 +15,64 @@ import (
 
 var VerifyInteraction = discordgo.VerifyInteraction
 
+var Now = time.Now
+
+var SeenInteractions = newReplayCache()
+
 func VerifyCommand(next httprouter.Handle) httprouter.Handle {
 	return func(response http.ResponseWriter, request *http.Request, params httprouter.Params) {
 		response.Header().Set("Content-Type", "application/json; charset=UTF-8")
-		
+
 		publicKeyBytes, err := hex.DecodeString(config.AppConfig.DISCORD_PUBLIC_KEY)
 		if err != nil {
 			errors.HandleError(response, err)
 			return
 		}
-		
+
 		result := VerifyInteraction(request, publicKeyBytes)
 		if !result {
 			errors.HandleError(response, errors.NewUnauthorized("Unauthorized Access", nil))
 			return
 		}
-		
+
+		if !isFresh(request.Header.Get("X-Signature-Timestamp")) {
+			errors.HandleError(response, errors.NewUnauthorized("Stale Request", nil))
+			return
+		}
+
+		if isReplayed(request) {
+			errors.HandleError(response, errors.NewUnauthorized("Replayed Request", nil))
+			return
+		}
+
 		next(response, request, params)
 	}
 }
+
+// isFresh reports whether the signed timestamp lies within INTERACTION_MAX_AGE of now.
+func isFresh(timestamp string) bool {
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+	skew := Now().Sub(time.Unix(seconds, 0))
+	if skew < 0 {
+		skew = -skew
+	}
+	return skew <= config.AppConfig.INTERACTION_MAX_AGE
+}
+
+// isReplayed reports whether the interaction ID in the request body has been
+// seen before. Requests without an ID are left to the handler to reject.
+func isReplayed(request *http.Request) bool {
+	payload := bufferBody(request)
+	interaction := struct {
+		ID string `json:"id"`
+	}{}
+	if err := json.Unmarshal(payload, &interaction); err != nil || interaction.ID == "" {
+		return false
+	}
+	// A request stays fresh for INTERACTION_MAX_AGE on either side of its
+	// timestamp, so its ID must be remembered for twice that long.
+	return SeenInteractions.CheckAndStore(interaction.ID, Now(), 2*config.AppConfig.INTERACTION_MAX_AGE)
+}
@@ -1,10 +1,13 @@
 package middleware_test
 
 import (
+	"bytes"
 	"crypto/ed25519"
 	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 
 	"github.com/Real-Dev-Squad/discord-service/config"
 	"github.com/Real-Dev-Squad/discord-service/middleware"
@@ -39,6 +42,7 @@ func TestVerifyCommand(t *testing.T) {
 
 		w := httptest.NewRecorder()
 		r, _ := http.NewRequest("POST", "/", nil)
+		r.Header.Set("X-Signature-Timestamp", strconv.FormatInt(time.Now().Unix(), 10))
 		router.ServeHTTP(w, r)
 		assert.Equal(t, http.StatusOK, w.Code)
 		assert.Equal(t, true, testControllerCalled)
@@ -70,3 +74,101 @@ func TestVerifyCommand(t *testing.T) {
 		assert.Equal(t, http.StatusInternalServerError, w.Code)
 	})
 }
+
+func TestVerifyCommandReplayProtection(t *testing.T) {
+	originalFunc := middleware.VerifyInteraction
+	originalNow := middleware.Now
+	originalMaxAge := config.AppConfig.INTERACTION_MAX_AGE
+	defer func() {
+		middleware.VerifyInteraction = originalFunc
+		middleware.Now = originalNow
+		config.AppConfig.INTERACTION_MAX_AGE = originalMaxAge
+	}()
+	middleware.VerifyInteraction = func(r *http.Request, key ed25519.PublicKey) bool {
+		return true
+	}
+	config.AppConfig.INTERACTION_MAX_AGE = time.Minute
+	now := time.Unix(1700000000, 0)
+	middleware.Now = func() time.Time { return now }
+
+	send := func(timestamp string, body string) *httptest.ResponseRecorder {
+		router := setup()
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/", bytes.NewBufferString(body))
+		r.Header.Set("X-Signature-Timestamp", timestamp)
+		router.ServeHTTP(w, r)
+		return w
+	}
+	timestampAt := func(offset time.Duration) string {
+		return strconv.FormatInt(now.Add(offset).Unix(), 10)
+	}
+
+	t.Run("Should accept a request signed within the allowed skew", func(t *testing.T) {
+		middleware.SeenInteractions.Reset()
+		w := send(timestampAt(-30*time.Second), `{"id":"1"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, testControllerCalled)
+	})
+
+	t.Run("Should reject a request signed too long ago", func(t *testing.T) {
+		middleware.SeenInteractions.Reset()
+		w := send(timestampAt(-2*time.Minute), `{"id":"1"}`)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.Contains(t, w.Body.String(), "Stale Request")
+		assert.False(t, testControllerCalled)
+	})
+
+	t.Run("Should reject a request signed too far in the future", func(t *testing.T) {
+		middleware.SeenInteractions.Reset()
+		w := send(timestampAt(2*time.Minute), `{"id":"1"}`)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.False(t, testControllerCalled)
+	})
+
+	t.Run("Should reject a request with a malformed timestamp", func(t *testing.T) {
+		middleware.SeenInteractions.Reset()
+		w := send("yesterday", `{"id":"1"}`)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.False(t, testControllerCalled)
+	})
+
+	t.Run("Should reject a replayed interaction", func(t *testing.T) {
+		middleware.SeenInteractions.Reset()
+		first := send(timestampAt(0), `{"id":"1"}`)
+		assert.Equal(t, http.StatusOK, first.Code)
+
+		replayed := send(timestampAt(0), `{"id":"1"}`)
+		assert.Equal(t, http.StatusUnauthorized, replayed.Code)
+		assert.Contains(t, replayed.Body.String(), "Replayed Request")
+		assert.False(t, testControllerCalled)
+	})
+
+	t.Run("Should accept distinct interactions", func(t *testing.T) {
+		middleware.SeenInteractions.Reset()
+		assert.Equal(t, http.StatusOK, send(timestampAt(0), `{"id":"1"}`).Code)
+		assert.Equal(t, http.StatusOK, send(timestampAt(0), `{"id":"2"}`).Code)
+	})
+
+	t.Run("Should forget interactions once they can no longer pass the timestamp check", func(t *testing.T) {
+		middleware.SeenInteractions.Reset()
+		assert.Equal(t, http.StatusOK, send(timestampAt(0), `{"id":"1"}`).Code)
+		now = now.Add(3 * time.Minute)
+		assert.Equal(t, http.StatusOK, send(timestampAt(0), `{"id":"1"}`).Code)
+	})
+
+	t.Run("Should pass the request body on to the next handler", func(t *testing.T) {
+		middleware.SeenInteractions.Reset()
+		var received string
+		router := httprouter.New()
+		router.POST("/", middleware.VerifyCommand(func(response http.ResponseWriter, request *http.Request, params httprouter.Params) {
+			body := new(bytes.Buffer)
+			body.ReadFrom(request.Body)
+			received = body.String()
+		}))
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/", bytes.NewBufferString(`{"id":"3"}`))
+		r.Header.Set("X-Signature-Timestamp", timestampAt(0))
+		router.ServeHTTP(w, r)
+		assert.Equal(t, `{"id":"3"}`, received)
+	})
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Real-Dev-Squad/discord-service/config"
+	"github.com/Real-Dev-Squad/discord-service/errors"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/julienschmidt/httprouter"
+)
+
+// VerifyServiceToken only lets through requests carrying a bearer token signed
+// by the RDS backend with the key matching RDS_PUBLIC_KEY.
+func VerifyServiceToken(next httprouter.Handle) httprouter.Handle {
+	return func(response http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		if config.AppConfig.RDS_PUBLIC_KEY == "" {
+			errors.HandleError(response, errors.NewUnauthorized("Unauthorized Access", nil))
+			return
+		}
+
+		publicKey, err := jwt.ParseRSAPublicKeyFromPEM([]byte(config.AppConfig.RDS_PUBLIC_KEY))
+		if err != nil {
+			errors.HandleError(response, err)
+			return
+		}
+
+		tokenString, ok := strings.CutPrefix(request.Header.Get("Authorization"), "Bearer ")
+		if !ok || tokenString == "" {
+			errors.HandleError(response, errors.NewUnauthorized("Unauthorized Access", nil))
+			return
+		}
+
+		_, err = jwt.Parse(tokenString, func(token *jwt.Token) (any, error) {
+			return publicKey, nil
+		}, jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}), jwt.WithExpirationRequired())
+		if err != nil {
+			errors.HandleError(response, errors.NewUnauthorized("Unauthorized Access", err))
+			return
+		}
+
+		next(response, request, params)
+	}
+}
@@ -0,0 +1,88 @@
+package middleware_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Real-Dev-Squad/discord-service/config"
+	"github.com/Real-Dev-Squad/discord-service/middleware"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+func signToken(t *testing.T, key *rsa.PrivateKey, claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(key)
+	assert.NoError(t, err)
+	return token
+}
+
+func TestVerifyServiceToken(t *testing.T) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	publicKeyBytes, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	assert.NoError(t, err)
+
+	originalKey := config.AppConfig.RDS_PUBLIC_KEY
+	defer func() { config.AppConfig.RDS_PUBLIC_KEY = originalKey }()
+	config.AppConfig.RDS_PUBLIC_KEY = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyBytes}))
+
+	send := func(authorization string) *httptest.ResponseRecorder {
+		testControllerCalled = false
+		router := httprouter.New()
+		router.POST("/", middleware.VerifyServiceToken(testController))
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/", nil)
+		if authorization != "" {
+			r.Header.Set("Authorization", authorization)
+		}
+		router.ServeHTTP(w, r)
+		return w
+	}
+	validClaims := jwt.MapClaims{"exp": time.Now().Add(time.Minute).Unix()}
+
+	t.Run("Should call the next handler for a valid token", func(t *testing.T) {
+		w := send("Bearer " + signToken(t, privateKey, validClaims))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.True(t, testControllerCalled)
+	})
+
+	t.Run("Should reject requests without a token", func(t *testing.T) {
+		w := send("")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.False(t, testControllerCalled)
+	})
+
+	t.Run("Should reject tokens signed with another key", func(t *testing.T) {
+		w := send("Bearer " + signToken(t, otherKey, validClaims))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		assert.False(t, testControllerCalled)
+	})
+
+	t.Run("Should reject expired tokens", func(t *testing.T) {
+		w := send("Bearer " + signToken(t, privateKey, jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()}))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Should reject tokens without an expiry", func(t *testing.T) {
+		w := send("Bearer " + signToken(t, privateKey, jwt.MapClaims{}))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Should reject every request when no public key is configured", func(t *testing.T) {
+		config.AppConfig.RDS_PUBLIC_KEY = ""
+		defer func() {
+			config.AppConfig.RDS_PUBLIC_KEY = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyBytes}))
+		}()
+		w := send("Bearer " + signToken(t, privateKey, validClaims))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
@@ -6,10 +6,6 @@ type SessionWrapper struct {
 	Session *discordgo.Session
 }
 
-func (s *SessionWrapper) Open() error {
-	return s.Session.Open()
-}
-
 func (s *SessionWrapper) Close() error {
 	return s.Session.Close()
 }
@@ -18,13 +14,30 @@ func (s *SessionWrapper) ApplicationCommandCreate(applicationID, guildID string,
 	return s.Session.ApplicationCommandCreate(applicationID, guildID, command)
 }
 
-func (sw *SessionWrapper) GetUerId() string {
-	return sw.Session.State.User.ID
+func (s *SessionWrapper) ApplicationCommands(applicationID, guildID string) ([]*discordgo.ApplicationCommand, error) {
+	return s.Session.ApplicationCommands(applicationID, guildID)
+}
+
+func (s *SessionWrapper) ApplicationCommandEdit(applicationID, guildID, commandID string, command *discordgo.ApplicationCommand) (*discordgo.ApplicationCommand, error) {
+	return s.Session.ApplicationCommandEdit(applicationID, guildID, commandID, command)
+}
+
+func (s *SessionWrapper) ApplicationCommandDelete(applicationID, guildID, commandID string) error {
+	return s.Session.ApplicationCommandDelete(applicationID, guildID, commandID)
+}
+
+// CurrentApplication looks up the application the bot token belongs to.
+func (s *SessionWrapper) CurrentApplication() (*discordgo.Application, error) {
+	return s.Session.Application("@me")
 }
 
+// SessionInterface is what managing slash commands needs from Discord. It
+// only makes REST calls, so it never needs a gateway connection.
 type SessionInterface interface {
-	Open() error
 	Close() error
 	ApplicationCommandCreate(applicationID, guildID string, command *discordgo.ApplicationCommand) (*discordgo.ApplicationCommand, error)
-	GetUerId() string
+	ApplicationCommands(applicationID, guildID string) ([]*discordgo.ApplicationCommand, error)
+	ApplicationCommandEdit(applicationID, guildID, commandID string, command *discordgo.ApplicationCommand) (*discordgo.ApplicationCommand, error)
+	ApplicationCommandDelete(applicationID, guildID, commandID string) error
+	CurrentApplication() (*discordgo.Application, error)
 }
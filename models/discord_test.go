@@ -17,12 +17,6 @@ func TestSessionWrapper(t *testing.T) {
 	mockSession := &discordgo.Session{}
 	sessionWrapper := &SessionWrapper{Session: mockSession}
 
-	t.Run("SessionWrapper should always implement open() method", func(t *testing.T) {
-		assert.Panics(t, func() {
-			sessionWrapper.Open()
-		}, "should panic when open() is called")
-	})
-
 	t.Run("SessionWrapper should always implement close() method", func(t *testing.T) {
 		assert.NotPanics(t, func() {
 			sessionWrapper.Close()
@@ -35,10 +29,28 @@ func TestSessionWrapper(t *testing.T) {
 		}, "should panic when applicationCommandCreate() is called")
 	})
 
-	t.Run("SessionWrapper should always implement getUerId() method", func(t *testing.T) {
+	t.Run("SessionWrapper should always implement applicationCommands() method", func(t *testing.T) {
+		assert.Panics(t, func() {
+			sessionWrapper.ApplicationCommands("1", "2")
+		}, "should panic when applicationCommands() is called")
+	})
+
+	t.Run("SessionWrapper should always implement applicationCommandEdit() method", func(t *testing.T) {
+		assert.Panics(t, func() {
+			sessionWrapper.ApplicationCommandEdit("1", "2", "3", command)
+		}, "should panic when applicationCommandEdit() is called")
+	})
+
+	t.Run("SessionWrapper should always implement applicationCommandDelete() method", func(t *testing.T) {
+		assert.Panics(t, func() {
+			sessionWrapper.ApplicationCommandDelete("1", "2", "3")
+		}, "should panic when applicationCommandDelete() is called")
+	})
+
+	t.Run("SessionWrapper should always implement currentApplication() method", func(t *testing.T) {
 		assert.Panics(t, func() {
-			sessionWrapper.GetUerId()
-		}, "should panic when getUerId() is called")
+			sessionWrapper.CurrentApplication()
+		}, "should panic when currentApplication() is called")
 	})
 
 }
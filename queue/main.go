@@ -1,6 +1,7 @@
 package queue
 
 import (
+	"context"
 	"errors"
 	"sync"
 
@@ -30,7 +31,7 @@ type Queue struct {
 
 func (q *Queue) dial() error {
 	var err error
-	q.Connection, err = amqp.Dial(config.AppConfig.QUEUE_URL)
+	q.Connection, err = amqp.Dial(config.AppConfig.QUEUE_URL.Value())
 	return err
 }
 
@@ -68,7 +69,7 @@ func InitQueueConnection(openSession sessionInterface) {
 		return err
 	}
 
-	err = utils.ExponentialBackoffRetry(config.AppConfig.MAX_RETRIES, f)
+	err = utils.ExponentialBackoffRetry(context.Background(), config.AppConfig.MAX_RETRIES, f)
 	if err != nil {
 		logrus.Errorf("Failed to initialize queue after %d attempts: %s", config.AppConfig.MAX_RETRIES, err)
 		return
@@ -87,6 +88,22 @@ var GetQueueInstance = func() *Queue {
 	return queueInstance
 }
 
+// Close closes the channel and then the connection to RabbitMQ, if they were
+// opened. Messages published afterwards fail.
+func Close() error {
+	if queueInstance == nil {
+		return nil
+	}
+	var errs []error
+	if queueInstance.Channel != nil {
+		errs = append(errs, queueInstance.Channel.Close())
+	}
+	if queueInstance.Connection != nil {
+		errs = append(errs, queueInstance.Connection.Close())
+	}
+	return errors.Join(errs...)
+}
+
 var SendMessage = func(message []byte) error {
 	queue := GetQueueInstance()
 
@@ -1,6 +1,7 @@
 package queue
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -68,7 +69,7 @@ func TestGetQueueInstance(t *testing.T) {
 	t.Run("Should use ExponentialBackoffRetry via GetQueueInstance", func(t *testing.T) {
 		attempt := 0
 		originalFunc := utils.ExponentialBackoffRetry
-		utils.ExponentialBackoffRetry = func(maxRetries int, operation func() error) error {
+		utils.ExponentialBackoffRetry = func(ctx context.Context, maxRetries int, operation func() error) error {
 			attempt++
 			return errors.New("error")
 		}
@@ -117,3 +118,14 @@ func TestSendMessage(t *testing.T) {
 		}, "SendMessage should panic when SendMessage returns error")
 	})
 }
+
+func TestClose(t *testing.T) {
+	t.Run("should do nothing when the queue was never opened", func(t *testing.T) {
+		original := queueInstance
+		defer func() { queueInstance = original }()
+		queueInstance = nil
+		assert.NoError(t, Close())
+		queueInstance = &Queue{}
+		assert.NoError(t, Close())
+	})
+}
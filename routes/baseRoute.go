@@ -7,7 +7,10 @@ import (
 )
 
 func SetupBaseRoutes(router *httprouter.Router) {
-	router.POST("/", middleware.VerifyCommand(controllers.DiscordBaseHandler))
+	router.POST("/", middleware.VerifyCommand(middleware.RecoverInteraction(controllers.DiscordBaseHandler)))
 	router.GET("/health", controllers.HealthCheckHandler)
-	router.POST("/queue", controllers.QueueHandler)
+	router.POST("/queue", middleware.RecoverQueue(controllers.QueueHandler))
+	router.POST("/roles/sync", middleware.VerifyServiceToken(controllers.RoleSyncHandler))
+	router.POST("/verify/complete", middleware.VerifyServiceToken(controllers.VerifyCompleteHandler))
+	router.GET("/debug/vars", middleware.VerifyServiceToken(controllers.MetricsHandler))
 }
@@ -1,11 +1,13 @@
 package routes
 
 import (
+	"context"
+	"net"
 	"net/http"
 
+	"github.com/Real-Dev-Squad/discord-service/config"
 	"github.com/julienschmidt/httprouter"
 	"github.com/rs/cors"
-	"github.com/sirupsen/logrus"
 )
 
 func SetupV1Routes() http.Handler {
@@ -20,10 +22,15 @@ func SetupV1Routes() http.Handler {
 	return corsConfig.Handler(router)
 }
 
-func Listen(listenAddress string) {
-	router := SetupV1Routes()
-	err := http.ListenAndServe(listenAddress, router)
-	if err != nil {
-		logrus.Fatal(err)
+// NewServer creates the server for the routes. Every request context derives
+// from base, so cancelling base tells in-flight handlers to give up.
+func NewServer(listenAddress string, base context.Context) *http.Server {
+	return &http.Server{
+		Addr:         listenAddress,
+		Handler:      SetupV1Routes(),
+		ReadTimeout:  config.AppConfig.HTTP_READ_TIMEOUT,
+		WriteTimeout: config.AppConfig.HTTP_WRITE_TIMEOUT,
+		IdleTimeout:  config.AppConfig.HTTP_IDLE_TIMEOUT,
+		BaseContext:  func(net.Listener) context.Context { return base },
 	}
 }
@@ -0,0 +1,42 @@
+package scheduler
+
+import (
+	"runtime/debug"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Every runs job once per interval until the returned stop function is called.
+// A job that panics is logged and does not stop later runs.
+func Every(name string, interval time.Duration, job func()) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				run(name, job)
+			}
+		}
+	}()
+	logrus.Infof("Scheduled %s every %s", name, interval)
+	return func() {
+		ticker.Stop()
+		close(done)
+	}
+}
+
+func run(name string, job func()) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			logrus.WithFields(logrus.Fields{
+				"job":   name,
+				"stack": string(debug.Stack()),
+			}).Errorf("Recovered from panic in scheduled job: %v", recovered)
+		}
+	}()
+	job()
+}
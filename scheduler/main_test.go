@@ -0,0 +1,31 @@
+package scheduler
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEvery(t *testing.T) {
+	t.Run("should run the job on every tick until stopped", func(t *testing.T) {
+		var runs atomic.Int32
+		stop := Every("test", 5*time.Millisecond, func() { runs.Add(1) })
+		assert.Eventually(t, func() bool { return runs.Load() >= 2 }, time.Second, time.Millisecond)
+		stop()
+		stopped := runs.Load()
+		time.Sleep(20 * time.Millisecond)
+		assert.LessOrEqual(t, runs.Load(), stopped+1)
+	})
+
+	t.Run("should keep running after a job panics", func(t *testing.T) {
+		var runs atomic.Int32
+		stop := Every("test", 5*time.Millisecond, func() {
+			runs.Add(1)
+			panic("boom")
+		})
+		defer stop()
+		assert.Eventually(t, func() bool { return runs.Load() >= 2 }, time.Second, time.Millisecond)
+	})
+}
@@ -21,7 +21,7 @@ func DiscordBaseService(response http.ResponseWriter, request *http.Request) {
 
 	var message dtos.DiscordMessage
 	if err = json.Unmarshal(payload, &message); err != nil {
-		errors.HandleError(response, err)
+		errors.HandleError(response, errors.NewBadRequest("Invalid Request Payload", err))
 		return
 	}
 
@@ -34,6 +34,10 @@ func DiscordBaseService(response http.ResponseWriter, request *http.Request) {
         return
 
 	case discordgo.InteractionApplicationCommand:
+		if message.Data == nil {
+			errors.HandleError(response, errors.NewBadRequest("Invalid Request Payload", nil))
+			return
+		}
 		MainService(&message)(response, request)
 		return
 
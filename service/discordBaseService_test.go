@@ -25,15 +25,15 @@ func TestDiscordBaseService(t *testing.T) {
 		assert.Equal(t, string(bytes) + "\n", rr.Body.String())
 	})
 
-	t.Run("should return internal server error when fails to unmarshal request payload", func(t *testing.T) {
+	t.Run("should return 400 status code when fails to unmarshal request payload", func(t *testing.T) {
 		r := httptest.NewRequest("POST", "/", bytes.NewBuffer([]byte(`"name": "listening"`)))
 		rr := httptest.NewRecorder()
 		DiscordBaseService(rr, r)
 		bytes, err := json.Marshal(map[string]string{
-			"error": "Internal server error",
+			"error": "Invalid Request Payload",
 		})
 		assert.NoError(t, err)
-		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
 		assert.Equal(t, string(bytes) + "\n", rr.Body.String())
 	})
 
@@ -51,6 +51,15 @@ func TestDiscordBaseService(t *testing.T) {
 		assert.Equal(t, string(bytes) + "\n", rr.Body.String())
 	})
 
+	t.Run("should return 400 status code when application command has no data", func(t *testing.T) {
+		msgByte, err := json.Marshal(dtos.DiscordMessage{Type: discordgo.InteractionApplicationCommand})
+		assert.NoError(t, err)
+		r := httptest.NewRequest("POST", "/", bytes.NewBuffer([]byte(msgByte)))
+		rr := httptest.NewRecorder()
+		DiscordBaseService(rr, r)
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
 	t.Run("should return success response when message type is interaction ping", func(t *testing.T) {
 		msgByte, err := json.Marshal(map[string]interface{}{"type": discordgo.InteractionPing})
 		assert.NoError(t, err)
@@ -1,9 +1,9 @@
 package service
 
 import (
-	"fmt"
 	"net/http"
 
+	"github.com/Real-Dev-Squad/discord-service/i18n"
 	"github.com/Real-Dev-Squad/discord-service/utils"
 	"github.com/bwmarrin/discordgo"
 )
@@ -12,7 +12,7 @@ func (s *CommandService) HelloService(response http.ResponseWriter, request *htt
 	messageResponse := &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
-			Content: fmt.Sprintf("Hey there <@%s>! Congratulations, you just executed your first slash command", s.discordMessage.Member.User.ID),
+			Content: i18n.T(s.discordMessage.PreferredLocale(), i18n.HelloGreeting, s.discordMessage.InvokingUser().ID),
 		},
 	}
 	utils.WriteJSONResponse(response, http.StatusOK, messageResponse)
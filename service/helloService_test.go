@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/Real-Dev-Squad/discord-service/fixtures"
+	"github.com/Real-Dev-Squad/discord-service/i18n"
 	_ "github.com/Real-Dev-Squad/discord-service/tests/helpers"
 
 	"github.com/bwmarrin/discordgo"
@@ -32,4 +33,19 @@ func TestHelloService(t *testing.T) {
 		assert.Equal(t, discordgo.InteractionResponseChannelMessageWithSource, response.Type)
 		assert.Equal(t, fmt.Sprintf("Hey there <@%s>! Congratulations, you just executed your first slash command", fixtures.HelloCommand.Member.User.ID), response.Data.Content)
 	})
+
+	t.Run("should greet in the locale of the user", func(t *testing.T) {
+		message := *fixtures.HelloCommand
+		message.Locale = discordgo.Hindi
+		message.GuildLocale = discordgo.EnglishUS
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/", nil)
+
+		CS.discordMessage = &message
+		CS.HelloService(w, r)
+
+		var response discordgo.InteractionResponse
+		assert.NoError(t, json.NewDecoder(w.Body).Decode(&response))
+		assert.Equal(t, i18n.T(discordgo.Hindi, i18n.HelloGreeting, message.Member.User.ID), response.Data.Content)
+	})
 }
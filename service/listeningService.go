@@ -7,6 +7,7 @@ import (
 
 	"github.com/Real-Dev-Squad/discord-service/dtos"
 	"github.com/Real-Dev-Squad/discord-service/errors"
+	"github.com/Real-Dev-Squad/discord-service/i18n"
 	"github.com/Real-Dev-Squad/discord-service/queue"
 	"github.com/Real-Dev-Squad/discord-service/utils"
 	"github.com/bwmarrin/discordgo"
@@ -14,36 +15,55 @@ import (
 
 func (s *CommandService) ListeningService(response http.ResponseWriter, request *http.Request) {
 	options := s.discordMessage.Data.Options[0]
+	locale := s.discordMessage.PreferredLocale()
 	msg := ""
 	requiresUpdate := false
+	// Member is only sent for interactions from a guild, a DM only has the
+	// user's display name.
+	nick := ""
+	if s.discordMessage.Member != nil {
+		nick = s.discordMessage.Member.Nick
+	} else if user := s.discordMessage.InvokingUser(); user != nil {
+		nick = user.GlobalName
+	}
 
-	if options.Value.(bool) && strings.Contains(s.discordMessage.Member.Nick, utils.NICKNAME_SUFFIX) {
-		msg = "You are already set to listen."
-	} else if !options.Value.(bool) && !strings.Contains(s.discordMessage.Member.Nick, utils.NICKNAME_SUFFIX) {
-		msg = "Your nickname remains unchanged."
+	// A command that changes nothing should not count against the cooldown.
+	if options.Value.(bool) && strings.Contains(nick, utils.NICKNAME_SUFFIX) {
+		refundCooldown(s.discordMessage)
+		msg = i18n.T(locale, i18n.ListeningAlreadyEnabled)
+	} else if !options.Value.(bool) && !strings.Contains(nick, utils.NICKNAME_SUFFIX) {
+		refundCooldown(s.discordMessage)
+		msg = i18n.T(locale, i18n.ListeningUnchanged)
 	} else {
 		requiresUpdate = true
-		msg = "Your nickname will be updated shortly."
+		msg = i18n.T(locale, i18n.ListeningUpdating)
 	}
 
 	if requiresUpdate {
 		dataPacket := dtos.DataPacket{
-			UserID:      s.discordMessage.Member.User.ID,
+			UserID:      s.discordMessage.InvokingUser().ID,
+			GuildID:     s.discordMessage.GuildId,
 			CommandName: utils.CommandNames.Listening,
 			MetaData: map[string]string{
-				"value":    fmt.Sprint(options.Value),
-				"nickname": s.discordMessage.Member.Nick,
+				"value":         fmt.Sprint(options.Value),
+				"userName":      s.discordMessage.InvokingUser().Username,
+				"interactionId": s.discordMessage.ID,
+				"token":         s.discordMessage.Token,
+				"applicationId": s.discordMessage.ApplicationId,
+				"locale":        string(locale),
 			},
 		}
 		
 		bytePacket, err := dtos.ToByte(&dataPacket)
 		if err != nil {
-			errors.HandleError(response, err)
+			refundCooldown(s.discordMessage)
+			errors.HandleInteractionErrorIn(response, err, locale)
 			return
 		}
 
 		if err := queue.SendMessage(bytePacket); err != nil {
-			errors.HandleError(response, err)
+			refundCooldown(s.discordMessage)
+			errors.HandleInteractionErrorIn(response, err, locale)
 			return
 		}
 	}
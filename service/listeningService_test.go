@@ -8,9 +8,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/Real-Dev-Squad/discord-service/config"
+	"github.com/Real-Dev-Squad/discord-service/cooldown"
 	"github.com/Real-Dev-Squad/discord-service/dtos"
+	appErrors "github.com/Real-Dev-Squad/discord-service/errors"
 	"github.com/Real-Dev-Squad/discord-service/queue"
 	"github.com/Real-Dev-Squad/discord-service/utils"
 	"github.com/bwmarrin/discordgo"
@@ -135,7 +138,64 @@ func TestListeningService(t *testing.T) {
 		assert.Contains(t, rr.Body.String(), "Your nickname will be updated shortly.")
 	})
 
-	t.Run("should return internal server error when fails to marshal data packet in json string bytes", func(t *testing.T) {
+	t.Run("should pass on the locale of the user", func(t *testing.T) {
+		originalFunc := queue.SendMessage
+		defer func() { queue.SendMessage = originalFunc }()
+		sent := &dtos.DataPacket{}
+		queue.SendMessage = func(message []byte) error { return json.Unmarshal(message, sent) }
+		options.Value = true
+		discordMessage := &dtos.DiscordMessage{
+			Data:   mockData,
+			Locale: discordgo.Hindi,
+			Member: &discordgo.Member{Nick: "joy", User: &discordgo.User{ID: "1"}},
+		}
+
+		(&CommandService{discordMessage: discordMessage}).ListeningService(httptest.NewRecorder(), httptest.NewRequest("POST", "/listening", nil))
+		assert.Equal(t, string(discordgo.Hindi), sent.MetaData["locale"])
+	})
+
+	t.Run("should not count commands that change nothing against the cooldown", func(t *testing.T) {
+		originalStore := cooldown.DefaultStore
+		defer func() { cooldown.DefaultStore = originalStore }()
+		cooldown.DefaultStore = cooldown.NewMemoryStore()
+
+		for _, test := range []struct {
+			value bool
+			nick  string
+		}{
+			{true, "joy" + utils.NICKNAME_SUFFIX},
+			{false, "joy"},
+		} {
+			_, ok := cooldown.Check(utils.CommandNames.Listening, "1", time.Minute, 1)
+			assert.True(t, ok)
+			options.Value = test.value
+			discordMessage := &dtos.DiscordMessage{
+				Data:   mockData,
+				Member: &discordgo.Member{Nick: test.nick, User: &discordgo.User{ID: "1"}},
+			}
+			(&CommandService{discordMessage: discordMessage}).ListeningService(httptest.NewRecorder(), httptest.NewRequest("POST", "/listening", nil))
+		}
+		_, ok := cooldown.Check(utils.CommandNames.Listening, "1", time.Minute, 1)
+		assert.True(t, ok)
+	})
+
+	t.Run("should read the nickname of the user when invoked from a direct message", func(t *testing.T) {
+		originalFunc := queue.SendMessage
+		defer func() { queue.SendMessage = originalFunc }()
+		queue.SendMessage = func(message []byte) error { return nil }
+		options.Value = true
+		discordMessage := &dtos.DiscordMessage{
+			Data: mockData,
+			User: &discordgo.User{ID: "1", GlobalName: "joy" + utils.NICKNAME_SUFFIX},
+		}
+
+		rr := httptest.NewRecorder()
+		commandService := &CommandService{discordMessage: discordMessage}
+		commandService.ListeningService(rr, httptest.NewRequest("POST", "/listening", nil))
+		assert.Contains(t, rr.Body.String(), "You are already set to listen.")
+	})
+
+	t.Run("should reply with an ephemeral error message when fails to marshal data packet in json string bytes", func(t *testing.T) {
 		originalFunc := dtos.ToByte
 		defer func() { dtos.ToByte = originalFunc }()
 		dtos.ToByte = func(d *dtos.DataPacket) ([]byte, error) {
@@ -145,10 +205,11 @@ func TestListeningService(t *testing.T) {
 		r := httptest.NewRequest("POST", "/listening", nil)
 		cs := &CommandService{discordMessage: discordMessageForFailureTest}
 		cs.ListeningService(w, r)
-		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), appErrors.DefaultInteractionMessage)
 	})
 
-	t.Run("should return internal server error when fails to send message to queue", func(t *testing.T) {
+	t.Run("should reply with an ephemeral error message when fails to send message to queue", func(t *testing.T) {
 		queue.SendMessage = func(message []byte) error {
 			return errors.New("error")
 		}
@@ -156,6 +217,7 @@ func TestListeningService(t *testing.T) {
 		r := httptest.NewRequest("POST", "/listening", nil)
 		cs := &CommandService{discordMessage: discordMessageForFailureTest}
 		cs.ListeningService(w, r)
-		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), appErrors.DefaultInteractionMessage)
 	})
 }
@@ -1,9 +1,16 @@
 package service
 
 import (
+	"math"
 	"net/http"
+	"time"
 
+	"github.com/Real-Dev-Squad/discord-service/commands/constants"
+	"github.com/Real-Dev-Squad/discord-service/config"
+	"github.com/Real-Dev-Squad/discord-service/cooldown"
 	"github.com/Real-Dev-Squad/discord-service/dtos"
+	"github.com/Real-Dev-Squad/discord-service/errors"
+	"github.com/Real-Dev-Squad/discord-service/i18n"
 	"github.com/Real-Dev-Squad/discord-service/utils"
 )
 
@@ -15,16 +22,85 @@ var CS = CommandService{}
 
 func MainService(discordMessage *dtos.DiscordMessage) func(response http.ResponseWriter, request *http.Request) {
 	CS.discordMessage = discordMessage
-	switch discordMessage.Data.Name {
-	case utils.CommandNames.Hello:
-		return CS.HelloService
-	case utils.CommandNames.Listening:
-		return CS.ListeningService
-	case utils.CommandNames.Verify:
-		return CS.Verify
-	default:
+	locale := discordMessage.PreferredLocale()
+	if config.AppConfig.DISABLED_COMMANDS.Disabled(discordMessage.Data.Name) {
+		return ephemeralResponse(i18n.T(locale, i18n.CommandDisabled, discordMessage.Data.Name))
+	}
+	if key, ok := checkCommandContext(discordMessage); !ok {
+		return ephemeralResponse(i18n.T(locale, key))
+	}
+	if remaining, ok := checkCooldown(discordMessage); !ok {
+		msg := i18n.T(locale, i18n.CommandCooldown, discordMessage.Data.Name, remaining)
+		return func(response http.ResponseWriter, request *http.Request) {
+			errors.HandleInteractionErrorIn(response, errors.NewTooManyRequests(msg, nil), locale)
+		}
+	}
+	service, ok := commandServices[discordMessage.Data.Name]
+	if !ok || constants.FindCommand(discordMessage.Data.Name) == nil {
+		msg := i18n.T(locale, i18n.CommandUnknown)
 		return func(response http.ResponseWriter, request *http.Request) {
-			response.WriteHeader(http.StatusOK)
+			errors.HandleInteractionErrorIn(response, errors.NewBadRequest(msg, nil), locale)
+		}
+	}
+	return func(response http.ResponseWriter, request *http.Request) {
+		service(&CS, response, request)
+	}
+}
+
+// commandServices handles the commands declared in the commands file. A command
+// is only dispatched when it is both declared and handled here.
+var commandServices = map[string]func(s *CommandService, response http.ResponseWriter, request *http.Request){
+	utils.CommandNames.Hello:     (*CommandService).HelloService,
+	utils.CommandNames.Listening: (*CommandService).ListeningService,
+	utils.CommandNames.Verify:    (*CommandService).Verify,
+}
+
+// checkCommandContext reports whether the command may run where it was invoked,
+// returning the message to show the user when it may not.
+func checkCommandContext(discordMessage *dtos.DiscordMessage) (i18n.Key, bool) {
+	command := constants.FindCommand(discordMessage.Data.Name)
+	if command == nil {
+		return "", true
+	}
+	if discordMessage.IsDM() {
+		if !command.AllowDM {
+			return i18n.CommandDMNotAllowed, false
 		}
+		return "", true
+	}
+	// Jobs act on the guild the command was invoked from, so a command runs in
+	// every guild it is registered in.
+	if discordMessage.GuildId != config.AppConfig.GUILD_ID && !command.AllowOtherGuilds && !constants.RegisteredIn(command, discordMessage.GuildId) {
+		return i18n.CommandOtherGuild, false
+	}
+	return "", true
+}
+
+// checkCooldown records the invocation against the command's cooldown and
+// returns the remaining wait, rounded up to a second, when the user is over it.
+func checkCooldown(discordMessage *dtos.DiscordMessage) (time.Duration, bool) {
+	command := constants.FindCommand(discordMessage.Data.Name)
+	user := discordMessage.InvokingUser()
+	if command == nil || command.Cooldown == nil || user == nil {
+		return 0, true
+	}
+	remaining, ok := cooldown.Check(command.Name, user.ID, command.Cooldown.Window, command.Cooldown.Burst)
+	if ok {
+		return 0, true
+	}
+	return time.Duration(math.Ceil(remaining.Seconds())) * time.Second, false
+}
+
+// refundCooldown gives back the use checkCooldown recorded, for commands that
+// failed to dispatch their job and so did nothing the cooldown should limit.
+func refundCooldown(discordMessage *dtos.DiscordMessage) {
+	if user := discordMessage.InvokingUser(); user != nil {
+		cooldown.Refund(discordMessage.Data.Name, user.ID)
+	}
+}
+
+func ephemeralResponse(msg string) func(response http.ResponseWriter, request *http.Request) {
+	return func(response http.ResponseWriter, request *http.Request) {
+		utils.WriteJSONResponse(response, http.StatusOK, dtos.NewEphemeralResponse(msg))
 	}
 }
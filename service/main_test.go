@@ -7,8 +7,12 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/Real-Dev-Squad/discord-service/commands/constants"
+	"github.com/Real-Dev-Squad/discord-service/config"
+	"github.com/Real-Dev-Squad/discord-service/cooldown"
 	"github.com/Real-Dev-Squad/discord-service/dtos"
 	"github.com/Real-Dev-Squad/discord-service/fixtures"
+	"github.com/Real-Dev-Squad/discord-service/i18n"
 	"github.com/Real-Dev-Squad/discord-service/queue"
 	_ "github.com/Real-Dev-Squad/discord-service/tests/helpers"
 	"github.com/Real-Dev-Squad/discord-service/utils"
@@ -37,6 +41,7 @@ func TestMainService(t *testing.T) {
 
 	t.Run("should trigger ListeningService when command name is listening", func(t *testing.T) {
 		discordMessage := &dtos.DiscordMessage{
+			GuildId: config.AppConfig.GUILD_ID,
 			Member: &discordgo.Member{
 				Nick: fmt.Sprintf("test%s", utils.NICKNAME_SUFFIX),
 			},
@@ -64,6 +69,7 @@ func TestMainService(t *testing.T) {
 
 	t.Run("should trigger VerifyService when command name is verify", func(t *testing.T) {
 		discordMessage := &dtos.DiscordMessage{
+			GuildId: config.AppConfig.GUILD_ID,
 			Member: &discordgo.Member{
 				User: &discordgo.User{},
 			},
@@ -102,6 +108,248 @@ func TestMainService(t *testing.T) {
 		handler(w, r)
 
 		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Body.String(), i18n.T(i18n.DefaultLocale, i18n.CommandUnknown))
 	})
 
+	t.Run("should have a service for every declared command", func(t *testing.T) {
+		for _, command := range constants.Commands {
+			assert.Contains(t, commandServices, command.Name)
+		}
+	})
+
+	t.Run("should not dispatch commands missing from the commands file", func(t *testing.T) {
+		originalCommands := constants.Commands
+		defer func() { constants.Commands = originalCommands }()
+		constants.Commands = originalCommands[:1]
+
+		discordMessage := &dtos.DiscordMessage{
+			GuildId: config.AppConfig.GUILD_ID,
+			Member: &discordgo.Member{
+				User: &discordgo.User{ID: "123456789012345678"},
+			},
+			Data: &dtos.Data{
+				ApplicationCommandInteractionData: discordgo.ApplicationCommandInteractionData{
+					Name: utils.CommandNames.Verify,
+				},
+			},
+		}
+
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		MainService(discordMessage)(w, r)
+		assert.Contains(t, w.Body.String(), i18n.T(i18n.DefaultLocale, i18n.CommandUnknown))
+	})
+
+	t.Run("should run HelloService when invoked from a direct message", func(t *testing.T) {
+		discordMessage := &dtos.DiscordMessage{
+			User: &discordgo.User{ID: "123456789012345678"},
+			Data: &dtos.Data{
+				ApplicationCommandInteractionData: discordgo.ApplicationCommandInteractionData{
+					Name: utils.CommandNames.Hello,
+				},
+			},
+		}
+
+		handler := MainService(discordMessage)
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		handler(w, r)
+		messageResponse := discordgo.InteractionResponse{}
+		err := json.Unmarshal(w.Body.Bytes(), &messageResponse)
+		assert.NoError(t, err)
+		assert.Contains(t, messageResponse.Data.Content, "<@123456789012345678>")
+	})
+
+	t.Run("should reject commands that are not allowed in direct messages", func(t *testing.T) {
+		discordMessage := &dtos.DiscordMessage{
+			User: &discordgo.User{ID: "123456789012345678"},
+			Data: &dtos.Data{
+				ApplicationCommandInteractionData: discordgo.ApplicationCommandInteractionData{
+					Name: utils.CommandNames.Verify,
+				},
+			},
+		}
+
+		handler := MainService(discordMessage)
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		handler(w, r)
+		messageResponse := discordgo.InteractionResponse{}
+		err := json.Unmarshal(w.Body.Bytes(), &messageResponse)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, i18n.T(i18n.DefaultLocale, i18n.CommandDMNotAllowed), messageResponse.Data.Content)
+		assert.Equal(t, discordgo.MessageFlagsEphemeral, messageResponse.Data.Flags)
+	})
+
+	t.Run("should reply that disabled commands are temporarily disabled", func(t *testing.T) {
+		originalFlags := config.AppConfig.DISABLED_COMMANDS
+		defer func() { config.AppConfig.DISABLED_COMMANDS = originalFlags }()
+		config.AppConfig.DISABLED_COMMANDS = config.NewCommandFlags(utils.CommandNames.Hello)
+
+		discordMessage := &dtos.DiscordMessage{
+			GuildId: config.AppConfig.GUILD_ID,
+			Locale:  discordgo.Hindi,
+			Member:  &discordgo.Member{User: &discordgo.User{ID: "123456789012345678"}},
+			Data: &dtos.Data{
+				ApplicationCommandInteractionData: discordgo.ApplicationCommandInteractionData{
+					Name: utils.CommandNames.Hello,
+				},
+			},
+		}
+
+		handler := MainService(discordMessage)
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		handler(w, r)
+		messageResponse := discordgo.InteractionResponse{}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &messageResponse))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, i18n.T(discordgo.Hindi, i18n.CommandDisabled, utils.CommandNames.Hello), messageResponse.Data.Content)
+		assert.Equal(t, discordgo.MessageFlagsEphemeral, messageResponse.Data.Flags)
+	})
+
+	t.Run("should reject commands that are not allowed in other guilds", func(t *testing.T) {
+		discordMessage := &dtos.DiscordMessage{
+			GuildId: "876543210987654321",
+			Member: &discordgo.Member{
+				User: &discordgo.User{ID: "123456789012345678"},
+			},
+			Data: &dtos.Data{
+				ApplicationCommandInteractionData: discordgo.ApplicationCommandInteractionData{
+					Name: utils.CommandNames.Listening,
+					Options: []*discordgo.ApplicationCommandInteractionDataOption{
+						{Value: true},
+					},
+				},
+			},
+		}
+
+		handler := MainService(discordMessage)
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		handler(w, r)
+		messageResponse := discordgo.InteractionResponse{}
+		err := json.Unmarshal(w.Body.Bytes(), &messageResponse)
+		assert.NoError(t, err)
+		assert.Equal(t, i18n.T(i18n.DefaultLocale, i18n.CommandOtherGuild), messageResponse.Data.Content)
+	})
+
+	t.Run("should run commands in every guild they are registered in, on that guild", func(t *testing.T) {
+		originalGuilds := config.AppConfig.COMMAND_GUILD_IDS
+		originalSendMessage := queue.SendMessage
+		defer func() {
+			config.AppConfig.COMMAND_GUILD_IDS = originalGuilds
+			queue.SendMessage = originalSendMessage
+		}()
+		config.AppConfig.COMMAND_GUILD_IDS = []string{config.AppConfig.GUILD_ID, "876543210987654321"}
+		sent := &dtos.DataPacket{}
+		queue.SendMessage = func(message []byte) error { return json.Unmarshal(message, sent) }
+
+		discordMessage := &dtos.DiscordMessage{
+			GuildId: "876543210987654321",
+			Member: &discordgo.Member{
+				User: &discordgo.User{ID: "123456789012345678"},
+			},
+			Data: &dtos.Data{
+				ApplicationCommandInteractionData: discordgo.ApplicationCommandInteractionData{
+					Name: utils.CommandNames.Listening,
+					Options: []*discordgo.ApplicationCommandInteractionDataOption{
+						{Value: true},
+					},
+				},
+			},
+		}
+
+		handler := MainService(discordMessage)
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		handler(w, r)
+		assert.NotContains(t, w.Body.String(), i18n.T(i18n.DefaultLocale, i18n.CommandOtherGuild))
+		assert.Equal(t, "876543210987654321", sent.GuildID)
+	})
+
+	t.Run("should allow dev commands in the dev guild", func(t *testing.T) {
+		originalDevGuild := config.AppConfig.DEV_GUILD_ID
+		originalScope := config.AppConfig.REGISTRATION_SCOPE
+		defer func() {
+			config.AppConfig.DEV_GUILD_ID = originalDevGuild
+			config.AppConfig.REGISTRATION_SCOPE = originalScope
+		}()
+		config.AppConfig.DEV_GUILD_ID = "876543210987654321"
+		config.AppConfig.REGISTRATION_SCOPE = config.RegisterDeclared
+		originalCommands := constants.Commands
+		defer func() { constants.Commands = originalCommands }()
+		constants.Commands = append([]*dtos.CommandDefinition{{ApplicationCommand: &discordgo.ApplicationCommand{Name: "preview"}, Scope: dtos.ScopeDevGuild}}, originalCommands...)
+
+		invoke := func(guildID string) bool {
+			_, ok := checkCommandContext(&dtos.DiscordMessage{
+				GuildId: guildID,
+				Data:    &dtos.Data{ApplicationCommandInteractionData: discordgo.ApplicationCommandInteractionData{Name: "preview"}},
+			})
+			return ok
+		}
+		assert.True(t, invoke("876543210987654321"))
+		assert.False(t, invoke("998877665544332211"))
+	})
+
+	t.Run("should reject commands invoked again before their cooldown expires", func(t *testing.T) {
+		originalStore := cooldown.DefaultStore
+		defer func() { cooldown.DefaultStore = originalStore }()
+		cooldown.DefaultStore = cooldown.NewMemoryStore()
+
+		discordMessage := &dtos.DiscordMessage{
+			GuildId: config.AppConfig.GUILD_ID,
+			Member: &discordgo.Member{
+				User: &discordgo.User{ID: "123456789012345678"},
+			},
+			Data: &dtos.Data{
+				ApplicationCommandInteractionData: discordgo.ApplicationCommandInteractionData{
+					Name: utils.CommandNames.Verify,
+				},
+			},
+		}
+
+		first := httptest.NewRecorder()
+		r, _ := http.NewRequest("GET", "/", nil)
+		MainService(discordMessage)(first, r)
+		assert.Contains(t, first.Body.String(), "Your request is being processed.")
+
+		second := httptest.NewRecorder()
+		MainService(discordMessage)(second, r)
+		messageResponse := discordgo.InteractionResponse{}
+		err := json.Unmarshal(second.Body.Bytes(), &messageResponse)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, second.Code)
+		assert.Equal(t, "You can use /verify again in 1m0s.", messageResponse.Data.Content)
+		assert.Equal(t, discordgo.MessageFlagsEphemeral, messageResponse.Data.Flags)
+	})
+
+	t.Run("should not hold a failed dispatch against the cooldown", func(t *testing.T) {
+		originalStore := cooldown.DefaultStore
+		defer func() { cooldown.DefaultStore = originalStore }()
+		cooldown.DefaultStore = cooldown.NewMemoryStore()
+		defer func() { queue.SendMessage = func(data []byte) error { return nil } }()
+		queue.SendMessage = func(data []byte) error { return fmt.Errorf("queue error") }
+
+		discordMessage := &dtos.DiscordMessage{
+			GuildId: config.AppConfig.GUILD_ID,
+			Member: &discordgo.Member{
+				User: &discordgo.User{ID: "123456789012345678"},
+			},
+			Data: &dtos.Data{
+				ApplicationCommandInteractionData: discordgo.ApplicationCommandInteractionData{
+					Name: utils.CommandNames.Verify,
+				},
+			},
+		}
+
+		r, _ := http.NewRequest("GET", "/", nil)
+		MainService(discordMessage)(httptest.NewRecorder(), r)
+
+		queue.SendMessage = func(data []byte) error { return nil }
+		retry := httptest.NewRecorder()
+		MainService(discordMessage)(retry, r)
+		assert.Contains(t, retry.Body.String(), "Your request is being processed.")
+	})
 }
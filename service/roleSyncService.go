@@ -0,0 +1,47 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/Real-Dev-Squad/discord-service/dtos"
+	"github.com/Real-Dev-Squad/discord-service/errors"
+	"github.com/Real-Dev-Squad/discord-service/queue"
+	"github.com/Real-Dev-Squad/discord-service/utils"
+)
+
+// RoleSyncRequest asks for the roles of UserID, or of every guild member when
+// UserID is empty, to be reconciled with their RDS roles.
+type RoleSyncRequest struct {
+	UserID string `json:"userId"`
+	DryRun bool   `json:"dryRun"`
+}
+
+// RoleSyncService enqueues a role sync job and accepts the request right away.
+func RoleSyncService(response http.ResponseWriter, request *http.Request) {
+	defer request.Body.Close()
+	body := &RoleSyncRequest{}
+	if err := json.NewDecoder(request.Body).Decode(body); err != nil {
+		errors.HandleError(response, errors.NewBadRequest("Invalid Request Payload", err))
+		return
+	}
+
+	dataPacket := dtos.DataPacket{
+		UserID:      body.UserID,
+		CommandName: utils.CommandNames.RoleSync,
+		MetaData: map[string]string{
+			"dryRun": strconv.FormatBool(body.DryRun),
+		},
+	}
+	bytePacket, err := dtos.ToByte(&dataPacket)
+	if err != nil {
+		errors.HandleError(response, err)
+		return
+	}
+	if err := queue.SendMessage(bytePacket); err != nil {
+		errors.HandleError(response, err)
+		return
+	}
+	utils.WriteJSONResponse(response, http.StatusAccepted, body)
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Real-Dev-Squad/discord-service/dtos"
+	"github.com/Real-Dev-Squad/discord-service/queue"
+	"github.com/Real-Dev-Squad/discord-service/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRoleSyncService(t *testing.T) {
+	originalSendMessage := queue.SendMessage
+	defer func() {
+		queue.SendMessage = originalSendMessage
+	}()
+
+	t.Run("should enqueue a role sync job for the user", func(t *testing.T) {
+		dataPacket := &dtos.DataPacket{}
+		queue.SendMessage = func(message []byte) error {
+			return dataPacket.FromByte(message)
+		}
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/roles/sync", strings.NewReader(`{"userId": "1", "dryRun": true}`))
+		RoleSyncService(w, r)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Equal(t, "1", dataPacket.UserID)
+		assert.Equal(t, utils.CommandNames.RoleSync, dataPacket.CommandName)
+		assert.Equal(t, "true", dataPacket.MetaData["dryRun"])
+	})
+
+	t.Run("should enqueue a full guild sweep when no user is given", func(t *testing.T) {
+		dataPacket := &dtos.DataPacket{}
+		queue.SendMessage = func(message []byte) error {
+			return dataPacket.FromByte(message)
+		}
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/roles/sync", strings.NewReader(`{}`))
+		RoleSyncService(w, r)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Equal(t, "", dataPacket.UserID)
+		assert.Equal(t, "false", dataPacket.MetaData["dryRun"])
+	})
+
+	t.Run("should return 400 for a malformed body", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/roles/sync", strings.NewReader("malformed"))
+		RoleSyncService(w, r)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should return 500 when the job cannot be enqueued", func(t *testing.T) {
+		queue.SendMessage = func(message []byte) error {
+			return errors.New("queue error")
+		}
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/roles/sync", strings.NewReader(`{"userId": "1"}`))
+		RoleSyncService(w, r)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
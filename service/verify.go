@@ -7,6 +7,7 @@ import (
 
 	"github.com/Real-Dev-Squad/discord-service/dtos"
 	"github.com/Real-Dev-Squad/discord-service/errors"
+	"github.com/Real-Dev-Squad/discord-service/i18n"
 	"github.com/Real-Dev-Squad/discord-service/queue"
 	"github.com/Real-Dev-Squad/discord-service/utils"
 	"github.com/bwmarrin/discordgo"
@@ -21,38 +22,49 @@ func (s *CommandService) Verify(response http.ResponseWriter, request *http.Requ
 		}
 	}
 	
+	user := s.discordMessage.InvokingUser()
+	locale := s.discordMessage.PreferredLocale()
 	message := &dtos.DataPacket{
-		UserID:      s.discordMessage.Member.User.ID,
+		UserID:      user.ID,
+		GuildID:     s.discordMessage.GuildId,
 		CommandName: utils.CommandNames.Verify,
 		MetaData: map[string]string{
-			"userAvatarHash":  s.discordMessage.Member.Avatar,
-			"userName":        s.discordMessage.Member.User.Username,
-			"discriminator":   s.discordMessage.Member.User.Discriminator,
-			"discordJoinedAt": s.discordMessage.Member.JoinedAt.Format(time.RFC3339),
-			"dev":             dev,
-			"channelId":       s.discordMessage.ChannelId,
-			"token":           s.discordMessage.Token,
-			"applicationId":   s.discordMessage.ApplicationId,
+			"userAvatarHash": "",
+			"userName":       user.Username,
+			"discriminator":  user.Discriminator,
+			"dev":            dev,
+			"channelId":      s.discordMessage.ChannelId,
+			"token":          s.discordMessage.Token,
+			"applicationId":  s.discordMessage.ApplicationId,
+			"interactionId":  s.discordMessage.ID,
+			"locale":         string(locale),
 		},
 	}
+	// Member is only sent for interactions from a guild.
+	if member := s.discordMessage.Member; member != nil {
+		message.MetaData["userAvatarHash"] = member.Avatar
+		message.MetaData["discordJoinedAt"] = member.JoinedAt.Format(time.RFC3339)
+	}
 
 	messageBytes, err := json.Marshal(message)
 	if err != nil {
 		logrus.Errorf("Failed to convert data packet to json bytes: %v", err)
-		errors.HandleError(response, err)
+		refundCooldown(s.discordMessage)
+		errors.HandleInteractionErrorIn(response, err, locale)
 		return
 	}
 
 	if err := queue.SendMessage(messageBytes); err != nil {
 		logrus.Errorf("Failed to send data packet to queue: %v", err)
-		errors.HandleError(response, err)
+		refundCooldown(s.discordMessage)
+		errors.HandleInteractionErrorIn(response, err, locale)
 		return
 	}
 
 	res := &discordgo.InteractionResponse{
 		Type: discordgo.InteractionResponseChannelMessageWithSource,
 		Data: &discordgo.InteractionResponseData{
-			Content: "Your request is being processed.",
+			Content: i18n.T(locale, i18n.RequestProcessing),
 			Flags:   discordgo.MessageFlagsEphemeral,
 		},
 	}
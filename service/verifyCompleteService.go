@@ -0,0 +1,60 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Real-Dev-Squad/discord-service/dtos"
+	"github.com/Real-Dev-Squad/discord-service/errors"
+	"github.com/Real-Dev-Squad/discord-service/queue"
+	"github.com/Real-Dev-Squad/discord-service/utils"
+)
+
+// VerifyCompleteRequest is sent by the RDS backend once a user linked their
+// Discord account. ApplicationID and Token reference the /verify interaction
+// and are optional, as are the Discord locale the confirmation is sent in and
+// the guild the role is granted in, GUILD_ID when empty.
+type VerifyCompleteRequest struct {
+	UserID        string `json:"userId"`
+	GuildID       string `json:"guildId"`
+	UserName      string `json:"userName"`
+	ApplicationID string `json:"applicationId"`
+	Token         string `json:"token"`
+	Locale        string `json:"locale"`
+}
+
+// VerifyCompleteService enqueues the job that welcomes a newly verified user.
+func VerifyCompleteService(response http.ResponseWriter, request *http.Request) {
+	defer request.Body.Close()
+	body := &VerifyCompleteRequest{}
+	if err := json.NewDecoder(request.Body).Decode(body); err != nil {
+		errors.HandleError(response, errors.NewBadRequest("Invalid Request Payload", err))
+		return
+	}
+	if body.UserID == "" {
+		errors.HandleError(response, errors.NewBadRequest("userId is required", nil))
+		return
+	}
+
+	dataPacket := dtos.DataPacket{
+		UserID:      body.UserID,
+		GuildID:     body.GuildID,
+		CommandName: utils.CommandNames.VerifyComplete,
+		MetaData: map[string]string{
+			"userName":      body.UserName,
+			"applicationId": body.ApplicationID,
+			"token":         body.Token,
+			"locale":        body.Locale,
+		},
+	}
+	bytePacket, err := dtos.ToByte(&dataPacket)
+	if err != nil {
+		errors.HandleError(response, err)
+		return
+	}
+	if err := queue.SendMessage(bytePacket); err != nil {
+		errors.HandleError(response, err)
+		return
+	}
+	utils.WriteJSONResponse(response, http.StatusAccepted, map[string]string{"userId": body.UserID})
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Real-Dev-Squad/discord-service/dtos"
+	"github.com/Real-Dev-Squad/discord-service/queue"
+	"github.com/Real-Dev-Squad/discord-service/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyCompleteService(t *testing.T) {
+	originalSendMessage := queue.SendMessage
+	defer func() {
+		queue.SendMessage = originalSendMessage
+	}()
+
+	t.Run("should enqueue a verify complete job", func(t *testing.T) {
+		dataPacket := &dtos.DataPacket{}
+		queue.SendMessage = func(message []byte) error {
+			return dataPacket.FromByte(message)
+		}
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/verify/complete", strings.NewReader(`{"userId": "1", "userName": "joy", "applicationId": "app", "token": "token"}`))
+		VerifyCompleteService(w, r)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Equal(t, "1", dataPacket.UserID)
+		assert.Equal(t, utils.CommandNames.VerifyComplete, dataPacket.CommandName)
+		assert.Equal(t, "joy", dataPacket.MetaData["userName"])
+		assert.Equal(t, "app", dataPacket.MetaData["applicationId"])
+		assert.Equal(t, "token", dataPacket.MetaData["token"])
+	})
+
+	t.Run("should return 400 when the user is missing", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/verify/complete", strings.NewReader(`{"userName": "joy"}`))
+		VerifyCompleteService(w, r)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should return 400 for a malformed body", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/verify/complete", strings.NewReader("malformed"))
+		VerifyCompleteService(w, r)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("should return 500 when the job cannot be enqueued", func(t *testing.T) {
+		queue.SendMessage = func(message []byte) error {
+			return errors.New("queue error")
+		}
+		w := httptest.NewRecorder()
+		r, _ := http.NewRequest("POST", "/verify/complete", strings.NewReader(`{"userId": "1"}`))
+		VerifyCompleteService(w, r)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
@@ -9,6 +9,8 @@ import (
 	"time"
 
 	"github.com/Real-Dev-Squad/discord-service/dtos"
+	appErrors "github.com/Real-Dev-Squad/discord-service/errors"
+	"github.com/Real-Dev-Squad/discord-service/i18n"
 	"github.com/Real-Dev-Squad/discord-service/queue"
 	"github.com/bwmarrin/discordgo"
 	"github.com/stretchr/testify/assert"
@@ -73,7 +75,47 @@ func TestVerify(t *testing.T) {
 		assert.Equal(t, rr.Body.Bytes(), resByte)
 	})
 
-	t.Run("should return internal server error when queue send message fails", func(t *testing.T) {
+	t.Run("should reply and pass on the locale of the guild", func(t *testing.T) {
+		originalSendMessage := queue.SendMessage
+		defer func() { queue.SendMessage = originalSendMessage }()
+
+		message := *baseMessage
+		message.GuildLocale = discordgo.Hindi
+		message.Data = &dtos.Data{}
+		service := &CommandService{discordMessage: &message}
+
+		sent := &dtos.DataPacket{}
+		queue.SendMessage = func(data []byte) error { return json.Unmarshal(data, sent) }
+
+		rr := httptest.NewRecorder()
+		service.Verify(rr, httptest.NewRequest("POST", "/verify", nil))
+		response := discordgo.InteractionResponse{}
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, i18n.T(discordgo.Hindi, i18n.RequestProcessing), response.Data.Content)
+		assert.Equal(t, string(discordgo.Hindi), sent.MetaData["locale"])
+	})
+
+	t.Run("should leave out member fields when invoked from a direct message", func(t *testing.T) {
+		originalSendMessage := queue.SendMessage
+		defer func() { queue.SendMessage = originalSendMessage }()
+		sent := &dtos.DataPacket{}
+		queue.SendMessage = func(data []byte) error { return json.Unmarshal(data, sent) }
+
+		message := &dtos.DiscordMessage{
+			User:  &discordgo.User{ID: "userID-123", Username: "testuser"},
+			Data:  &dtos.Data{},
+			Token: "interaction-token",
+		}
+		rr := httptest.NewRecorder()
+		(&CommandService{discordMessage: message}).Verify(rr, httptest.NewRequest("POST", "/verify", nil))
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, "userID-123", sent.UserID)
+		assert.Equal(t, "", sent.MetaData["userAvatarHash"])
+		assert.NotContains(t, sent.MetaData, "discordJoinedAt")
+	})
+
+	t.Run("should reply with an ephemeral error message when queue send message fails", func(t *testing.T) {
 		originalSendMessage := queue.SendMessage
 		defer func() {
 			queue.SendMessage = originalSendMessage
@@ -92,6 +134,10 @@ func TestVerify(t *testing.T) {
 		rr := httptest.NewRecorder()
 		service.Verify(rr, req)
 
-		assert.Equal(t, http.StatusInternalServerError, rr.Code)
+		response := discordgo.InteractionResponse{}
+		assert.NoError(t, json.Unmarshal(rr.Body.Bytes(), &response))
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.Equal(t, appErrors.DefaultInteractionMessage, response.Data.Content)
+		assert.Equal(t, discordgo.MessageFlagsEphemeral, response.Data.Flags)
 	})
 }
\ No newline at end of file
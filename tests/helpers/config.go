@@ -1,19 +1,21 @@
 package helpers
 
 import (
-	"os"
+	"github.com/Real-Dev-Squad/discord-service/config"
 )
 
+// init gives tests a complete configuration without reading the environment.
 func init() {
-	os.Setenv("PORT", "8080")
-	os.Setenv("DISCORD_PUBLIC_KEY", "8933e3749b4feb4d76169b26ed372af3c378f4353c2024fee0601f2a2e7918e1")
-	os.Setenv("GUILD_ID", "8933e3749b4feb4d76169b26ed372af3c378f4353c2024fee0601f2a2e7918e1")
-	os.Setenv("BOT_TOKEN", "8933e3749b4feb4d76169b26ed372af3c378f4353c2024fee0601f2a2e7918e1")
-	os.Setenv("QUEUE_NAME", "DISCORD_QUEUE")
-	os.Setenv("QUEUE_URL", "local:5672")
-	os.Setenv("ENV", "development")
-	os.Setenv("RDS_BASE_API_URL", "http://localhost:3000")
-	os.Setenv("MAIN_SITE_URL", "http://localhost:4200")
-	os.Setenv("BOT_PRIVATE_KEY", "<discord-bot-private-key>")
-	os.Setenv("VERIFICATION_SITE_URL", "http://localhost:3443")
+	config.AppConfig = config.Defaults()
+	config.AppConfig.Port = "8080"
+	config.AppConfig.DISCORD_PUBLIC_KEY = "8933e3749b4feb4d76169b26ed372af3c378f4353c2024fee0601f2a2e7918e1"
+	config.AppConfig.GUILD_ID = "112233445566778899"
+	config.AppConfig.COMMAND_GUILD_IDS = []string{config.AppConfig.GUILD_ID}
+	config.AppConfig.BOT_TOKEN = config.NewSecret("8933e3749b4feb4d76169b26ed372af3c378f4353c2024fee0601f2a2e7918e1")
+	config.AppConfig.QUEUE_URL = config.NewSecret("amqp://local:5672")
+	config.AppConfig.RDS_BASE_API_URL = "http://localhost:3000"
+	config.AppConfig.MAIN_SITE_URL = "http://localhost:4200"
+	config.AppConfig.BOT_PRIVATE_KEY = config.NewSecret("<discord-bot-private-key>")
+	config.AppConfig.VERIFICATION_SITE_URL = "http://localhost:3443"
+	config.AppConfig.REGISTRATION_SCOPE = config.RegisterDeclared
 }
@@ -9,4 +9,11 @@ var CommandNames = dtos.CommandNameTypes{
 	Hello:     "hello",
 	Listening: "listening",
 	Verify:    "verify",
+	// The commands below are not slash commands, they are enqueued by guild
+	// member events, the inbound API and scheduled jobs.
+	MemberSync:        "memberSync",
+	RoleSync:          "roleSync",
+	VerifyComplete:    "verifyComplete",
+	NicknameReconcile: "nicknameReconcile",
+	NicknameRepair:    "nicknameRepair",
 }
@@ -1,22 +1,35 @@
 package utils
 
 import (
+	"context"
 	"math"
 	"time"
 
 	"github.com/sirupsen/logrus"
 )
 
-var ExponentialBackoffRetry = func(maxRetries int, operation func() error) error {
+// ExponentialBackoffRetry runs operation until it succeeds or maxRetries
+// attempts failed. It gives up early, returning the error of ctx, once ctx is
+// done, so that a shutting down service does not keep retrying.
+var ExponentialBackoffRetry = func(ctx context.Context, maxRetries int, operation func() error) error {
 	var err error
 	for i := 0; i < maxRetries; i++ {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
 		err = operation()
 		if err == nil {
 			return nil
 		}
 		logrus.Errorf("Attempt %d: Operation failed: %s", i+1, err)
 		if i < maxRetries-1 {
-			time.Sleep(time.Duration(math.Pow(2, float64(i))) * time.Second)
+			timer := time.NewTimer(time.Duration(math.Pow(2, float64(i))) * time.Second)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
+			}
 		}
 	}
 	return err
@@ -1,8 +1,10 @@
 package utils
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -17,7 +19,7 @@ func TestExponentialBackoffRetry_Success(t *testing.T) {
 		return nil
 	}
 
-	err := ExponentialBackoffRetry(5, operation)
+	err := ExponentialBackoffRetry(context.Background(), 5, operation)
 	assert.NoError(t, err)
 	assert.Equal(t, 3, attempts)
 }
@@ -29,7 +31,7 @@ func TestExponentialBackoffRetry_Failure(t *testing.T) {
 		return errors.New("permanent error")
 	}
 
-	err := ExponentialBackoffRetry(3, operation)
+	err := ExponentialBackoffRetry(context.Background(), 3, operation)
 	assert.Error(t, err)
 	assert.Equal(t, 3, attempts)
 }
@@ -41,7 +43,7 @@ func TestExponentialBackoffRetry_NoRetries(t *testing.T) {
 		return errors.New("error")
 	}
 
-	err := ExponentialBackoffRetry(0, operation)
+	err := ExponentialBackoffRetry(context.Background(), 0, operation)
 	assert.Nil(t, err)
 	assert.Equal(t, 0, attempts)
 }
@@ -52,7 +54,33 @@ func TestExponentialBackoffRetry_ImmediateSuccess(t *testing.T) {
 		attempts++
 		return nil
 	}
-	err := ExponentialBackoffRetry(5, operation)
+	err := ExponentialBackoffRetry(context.Background(), 5, operation)
 	assert.NoError(t, err)
 	assert.Equal(t, 1, attempts)
 }
+
+func TestExponentialBackoffRetry_Cancelled(t *testing.T) {
+	t.Run("should stop waiting for the next attempt once the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		attempts := 0
+		operation := func() error {
+			attempts++
+			cancel()
+			return errors.New("error")
+		}
+		start := time.Now()
+		err := ExponentialBackoffRetry(ctx, 5, operation)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, 1, attempts)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+
+	t.Run("should not start an attempt once the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		attempts := 0
+		err := ExponentialBackoffRetry(ctx, 5, func() error { attempts++; return nil })
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Zero(t, attempts)
+	})
+}
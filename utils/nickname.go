@@ -0,0 +1,107 @@
+package utils
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// MAX_NICKNAME_LENGTH is the longest nickname Discord accepts, in characters.
+const MAX_NICKNAME_LENGTH = 32
+
+// Decoration is a status shown in a nickname as a prefix and a suffix.
+type Decoration struct {
+	Name   string
+	Prefix string
+	Suffix string
+}
+
+var (
+	ListeningDecoration = Decoration{Name: "listening", Prefix: NICKNAME_PREFIX, Suffix: NICKNAME_SUFFIX}
+	OOODecoration       = Decoration{Name: "ooo", Suffix: " (OOO)"}
+	OnCallDecoration    = Decoration{Name: "onCall", Prefix: "📟 "}
+)
+
+// NicknameDecorations lists the known decorations from the innermost to the
+// outermost, which is the order FormatNickname applies them in.
+var NicknameDecorations = []Decoration{ListeningDecoration, OOODecoration, OnCallDecoration}
+
+// FormatNickname wraps base in decorations, in the order of
+// NicknameDecorations whatever order they are passed in. Unknown decorations
+// are ignored. When the result would be longer than MAX_NICKNAME_LENGTH, base is
+// shortened so that every decoration is kept whole.
+func FormatNickname(base string, decorations ...Decoration) string {
+	active := orderDecorations(decorations)
+	decorationLength := 0
+	for _, decoration := range active {
+		decorationLength += utf8.RuneCountInString(decoration.Prefix) + utf8.RuneCountInString(decoration.Suffix)
+	}
+
+	nickName := truncateRunes(strings.TrimSpace(base), MAX_NICKNAME_LENGTH-decorationLength)
+	for _, decoration := range active {
+		nickName = decoration.Prefix + nickName + decoration.Suffix
+	}
+	return nickName
+}
+
+// ParseNickname removes the decorations of a nickname built by FormatNickname
+// and returns its base and the decorations it had, innermost first.
+func ParseNickname(nickName string) (string, []Decoration) {
+	found := []Decoration{}
+	for i := len(NicknameDecorations) - 1; i >= 0; i-- {
+		decoration := NicknameDecorations[i]
+		if hasDecoration(nickName, decoration) {
+			nickName = strings.TrimSuffix(strings.TrimPrefix(nickName, decoration.Prefix), decoration.Suffix)
+			found = append([]Decoration{decoration}, found...)
+		}
+	}
+	return nickName, found
+}
+
+// SetDecoration adds decoration to or removes it from nickName, keeping its
+// other decorations.
+func SetDecoration(nickName string, decoration Decoration, enabled bool) string {
+	base, decorations := ParseNickname(nickName)
+	kept := []Decoration{}
+	for _, current := range decorations {
+		if current.Name != decoration.Name {
+			kept = append(kept, current)
+		}
+	}
+	if enabled {
+		kept = append(kept, decoration)
+	}
+	return FormatNickname(base, kept...)
+}
+
+func hasDecoration(nickName string, decoration Decoration) bool {
+	if utf8.RuneCountInString(nickName) < utf8.RuneCountInString(decoration.Prefix)+utf8.RuneCountInString(decoration.Suffix) {
+		return false
+	}
+	return strings.HasPrefix(nickName, decoration.Prefix) && strings.HasSuffix(nickName, decoration.Suffix)
+}
+
+func orderDecorations(decorations []Decoration) []Decoration {
+	ordered := []Decoration{}
+	for _, known := range NicknameDecorations {
+		for _, decoration := range decorations {
+			if decoration.Name == known.Name {
+				ordered = append(ordered, known)
+				break
+			}
+		}
+	}
+	return ordered
+}
+
+// truncateRunes cuts s to at most max characters, dropping any space left at
+// the cut.
+func truncateRunes(s string, max int) string {
+	if max < 0 {
+		max = 0
+	}
+	runes := []rune(s)
+	if len(runes) <= max {
+		return s
+	}
+	return strings.TrimSpace(string(runes[:max]))
+}
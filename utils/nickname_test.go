@@ -0,0 +1,145 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"testing/quick"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// decorationsFromMask picks the known decorations whose bit is set in mask.
+func decorationsFromMask(mask uint8) []Decoration {
+	decorations := []Decoration{}
+	for i, decoration := range NicknameDecorations {
+		if mask&(1<<i) != 0 {
+			decorations = append(decorations, decoration)
+		}
+	}
+	return decorations
+}
+
+func names(decorations []Decoration) []string {
+	result := []string{}
+	for _, decoration := range decorations {
+		result = append(result, decoration.Name)
+	}
+	return result
+}
+
+func TestFormatNickname(t *testing.T) {
+	t.Run("should apply decorations in their defined order", func(t *testing.T) {
+		nickName := FormatNickname("joy", OnCallDecoration, ListeningDecoration, OOODecoration)
+		assert.Equal(t, OnCallDecoration.Prefix+NICKNAME_PREFIX+"joy"+NICKNAME_SUFFIX+OOODecoration.Suffix, nickName)
+	})
+
+	t.Run("should keep a short nickname as it is", func(t *testing.T) {
+		assert.Equal(t, "joy", FormatNickname("joy"))
+	})
+
+	t.Run("should shorten the base name and keep the decorations", func(t *testing.T) {
+		nickName := FormatNickname("a very long name that does not fit", ListeningDecoration)
+		assert.Equal(t, MAX_NICKNAME_LENGTH, utf8.RuneCountInString(nickName))
+		assert.True(t, strings.HasPrefix(nickName, NICKNAME_PREFIX))
+		assert.True(t, strings.HasSuffix(nickName, NICKNAME_SUFFIX))
+	})
+
+	t.Run("should count length in characters, not bytes", func(t *testing.T) {
+		base := strings.Repeat("é", 19)
+		assert.Equal(t, NICKNAME_PREFIX+base+NICKNAME_SUFFIX, FormatNickname(base, ListeningDecoration))
+	})
+
+	t.Run("should not leave a space where the base name was cut", func(t *testing.T) {
+		nickName := FormatNickname("abcdefghijklmnopqrstuvwxyz abcdefg", OOODecoration)
+		assert.Equal(t, "abcdefghijklmnopqrstuvwxyz"+OOODecoration.Suffix, nickName)
+	})
+}
+
+func TestParseNickname(t *testing.T) {
+	t.Run("should return a plain nickname without decorations", func(t *testing.T) {
+		base, decorations := ParseNickname("joy")
+		assert.Equal(t, "joy", base)
+		assert.Empty(t, decorations)
+	})
+
+	t.Run("should not mistake a lone prefix for a decoration", func(t *testing.T) {
+		base, decorations := ParseNickname(NICKNAME_PREFIX + "joy")
+		assert.Equal(t, NICKNAME_PREFIX+"joy", base)
+		assert.Empty(t, decorations)
+	})
+}
+
+func TestSetDecoration(t *testing.T) {
+	t.Run("should add a decoration and keep the others", func(t *testing.T) {
+		nickName := SetDecoration(FormatNickname("joy", ListeningDecoration), OOODecoration, true)
+		assert.Equal(t, NICKNAME_PREFIX+"joy"+NICKNAME_SUFFIX+OOODecoration.Suffix, nickName)
+	})
+
+	t.Run("should remove a decoration and keep the others", func(t *testing.T) {
+		nickName := SetDecoration(FormatNickname("joy", ListeningDecoration, OnCallDecoration), ListeningDecoration, false)
+		assert.Equal(t, OnCallDecoration.Prefix+"joy", nickName)
+	})
+
+	t.Run("should not add a decoration twice", func(t *testing.T) {
+		nickName := SetDecoration(FormatNickname("joy", ListeningDecoration), ListeningDecoration, true)
+		assert.Equal(t, NICKNAME_PREFIX+"joy"+NICKNAME_SUFFIX, nickName)
+	})
+}
+
+func TestNicknameProperties(t *testing.T) {
+	t.Run("should never exceed the maximum length", func(t *testing.T) {
+		property := func(base string, mask uint8) bool {
+			return utf8.RuneCountInString(FormatNickname(base, decorationsFromMask(mask)...)) <= MAX_NICKNAME_LENGTH
+		}
+		assert.NoError(t, quick.Check(property, nil))
+	})
+
+	t.Run("should keep every decoration whole", func(t *testing.T) {
+		property := func(base string, mask uint8) bool {
+			decorations := decorationsFromMask(mask)
+			_, parsed := ParseNickname(FormatNickname(base, decorations...))
+			return assert.ObjectsAreEqual(names(decorations), names(parsed))
+		}
+		assert.NoError(t, quick.Check(property, nil))
+	})
+
+	t.Run("should only ever shorten the base name", func(t *testing.T) {
+		property := func(base string, mask uint8) bool {
+			parsed, _ := ParseNickname(FormatNickname(base, decorationsFromMask(mask)...))
+			return strings.HasPrefix(strings.TrimSpace(base), parsed)
+		}
+		assert.NoError(t, quick.Check(property, nil))
+	})
+
+	t.Run("should format a parsed nickname back to itself", func(t *testing.T) {
+		property := func(base string, mask uint8) bool {
+			nickName := FormatNickname(base, decorationsFromMask(mask)...)
+			parsedBase, decorations := ParseNickname(nickName)
+			return FormatNickname(parsedBase, decorations...) == nickName
+		}
+		assert.NoError(t, quick.Check(property, nil))
+	})
+
+	t.Run("should reverse each decoration cleanly", func(t *testing.T) {
+		property := func(base string, mask uint8) bool {
+			decorations := decorationsFromMask(mask)
+			nickName := FormatNickname(base, decorations...)
+			parsedBase, _ := ParseNickname(nickName)
+			for _, decoration := range decorations {
+				removed := SetDecoration(nickName, decoration, false)
+				removedBase, remaining := ParseNickname(removed)
+				if removedBase != parsedBase {
+					return false
+				}
+				for _, other := range remaining {
+					if other.Name == decoration.Name {
+						return false
+					}
+				}
+			}
+			return true
+		}
+		assert.NoError(t, quick.Check(property, nil))
+	})
+}